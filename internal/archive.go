@@ -0,0 +1,431 @@
+package internal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// archiveVersion is the version of the archive document format
+// written by export. Restore also reads earlier versions: version 1
+// predates game status and time, so its games are all final, and
+// versions 1 and 2 predate line scores. Versions before 4 have no
+// players.
+const archiveVersion = 4
+
+// archive is a complete, stable-keyed copy of the data in a store
+type archive struct {
+	Version int             `json:"version"`
+	Sports  []archiveSport  `json:"sports"`
+	Leagues []archiveLeague `json:"leagues"`
+	Clubs   []archiveClub   `json:"clubs"`
+	Players []archivePlayer `json:"players,omitempty"`
+	Seasons []archiveSeason `json:"seasons"`
+	Games   []archiveGame   `json:"games"`
+}
+
+type archiveSport struct {
+	ID           int           `json:"id"`
+	Name         string        `json:"name"`
+	Periods      int           `json:"periods"`
+	PeriodName   string        `json:"period_name"`
+	OvertimeName string        `json:"overtime_name"`
+	Stats        []archiveStat `json:"stats,omitempty"`
+}
+
+type archiveStat struct {
+	ID   int    `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type archivePlayer struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type archiveLeague struct {
+	Code         string           `json:"code"`
+	Name         string           `json:"name"`
+	Sport        string           `json:"sport"`
+	ActiveSeason int              `json:"active_season,omitempty"`
+	ActiveClubs  []archiveClubRef `json:"active_clubs"`
+}
+
+type archiveClub struct {
+	ID         int    `json:"id"`
+	Iteration  int    `json:"iteration"`
+	Represents string `json:"represents"`
+	Nickname   string `json:"nickname"`
+}
+
+type archiveClubRef struct {
+	ID        int `json:"id"`
+	Iteration int `json:"iteration"`
+}
+
+type archiveSeason struct {
+	ID         int              `json:"id"`
+	League     string           `json:"league"`
+	Year       int              `json:"year"`
+	Type       string           `json:"type"`
+	Exhibition bool             `json:"exhibition"`
+	Clubs      []archiveClubRef `json:"clubs"`
+	Rosters    []archiveRoster  `json:"rosters,omitempty"`
+}
+
+type archiveRoster struct {
+	Club    int   `json:"club"`
+	Players []int `json:"players"`
+}
+
+type archiveGame struct {
+	ID        int               `json:"id"`
+	Season    int               `json:"season"`
+	Date      string            `json:"date"`
+	Title     string            `json:"title"`
+	Venue     string            `json:"venue"`
+	Status    string            `json:"status,omitempty"`
+	Time      string            `json:"time,omitempty"`
+	Home      int               `json:"home"`
+	HomeScore int               `json:"home_score"`
+	Away      int               `json:"away"`
+	AwayScore int               `json:"away_score"`
+	Periods   []archivePeriod   `json:"periods,omitempty"`
+	StatLines []archiveStatLine `json:"stat_lines,omitempty"`
+	Resources []archiveResource `json:"resources"`
+}
+
+// archiveStatLine keys the values of a player's stat line by stat ID
+type archiveStatLine struct {
+	Player int         `json:"player"`
+	Club   int         `json:"club"`
+	Values map[int]int `json:"values"`
+}
+
+type archivePeriod struct {
+	Period    int `json:"period"`
+	HomeScore int `json:"home_score"`
+	AwayScore int `json:"away_score"`
+}
+
+type archiveResource struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+func clubRefs(clubs []Club) []archiveClubRef {
+	refs := make([]archiveClubRef, len(clubs))
+	for i, club := range clubs {
+		refs[i] = archiveClubRef{club.ID, club.Iteration}
+	}
+	return refs
+}
+
+// exportArchive reads everything in db into an archive
+func exportArchive(db store) (archive, error) {
+	a := archive{Version: archiveVersion}
+
+	sports, err := db.sports()
+	if err != nil {
+		return a, err
+	}
+	a.Sports = make([]archiveSport, len(sports))
+	for i, sport := range sports {
+		stats, err := db.stats(sport)
+		if err != nil {
+			return a, err
+		}
+		a.Sports[i] = archiveSport{sport.ID, sport.Name,
+			sport.Periods, sport.PeriodName, sport.OvertimeName, nil}
+		for _, stat := range stats {
+			a.Sports[i].Stats = append(a.Sports[i].Stats, archiveStat{stat.ID, stat.Code, stat.Name})
+		}
+	}
+
+	players, err := db.players()
+	if err != nil {
+		return a, err
+	}
+	for _, player := range players {
+		a.Players = append(a.Players, archivePlayer{player.ID, player.Name})
+	}
+
+	clubs, err := db.clubs()
+	if err != nil {
+		return a, err
+	}
+	a.Clubs = make([]archiveClub, len(clubs))
+	for i, club := range clubs {
+		a.Clubs[i] = archiveClub{club.ID, club.Iteration, club.Represents, club.Nickname}
+	}
+
+	leagues, err := db.leagues()
+	if err != nil {
+		return a, err
+	}
+	a.Leagues = make([]archiveLeague, 0, len(leagues))
+	a.Seasons = make([]archiveSeason, 0)
+	for _, league := range leagues {
+		al := archiveLeague{Code: league.Code, Name: league.Name, Sport: league.Sport}
+
+		if active, err := db.activeSeason(league); err == nil {
+			al.ActiveSeason = active.ID
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return a, err
+		}
+
+		activeClubs, err := db.clubsByLeague(league, true)
+		if err != nil {
+			return a, err
+		}
+		al.ActiveClubs = clubRefs(activeClubs)
+		a.Leagues = append(a.Leagues, al)
+
+		filter := seasonFilter{}
+		filter.SetLeague(league)
+		seasons, err := db.seasons(filter)
+		if err != nil {
+			return a, err
+		}
+		for _, season := range seasons {
+			seasonClubs, err := db.clubsBySeason(season)
+			if err != nil {
+				return a, err
+			}
+			as := archiveSeason{
+				ID:         season.ID,
+				League:     league.Code,
+				Year:       season.Year,
+				Type:       season.Type,
+				Exhibition: season.Exhibition,
+				Clubs:      clubRefs(seasonClubs),
+			}
+			for _, club := range seasonClubs {
+				roster, err := db.roster(season, club)
+				if err != nil {
+					return a, err
+				}
+				if len(roster) == 0 {
+					continue
+				}
+				ar := archiveRoster{Club: club.ID}
+				for _, player := range roster {
+					ar.Players = append(ar.Players, player.ID)
+				}
+				as.Rosters = append(as.Rosters, ar)
+			}
+			a.Seasons = append(a.Seasons, as)
+		}
+	}
+
+	games, err := db.games(gameFilter{})
+	if err != nil {
+		return a, err
+	}
+	a.Games = make([]archiveGame, len(games))
+	for i, game := range games {
+		resources, err := db.resources(game)
+		if err != nil {
+			return a, err
+		}
+		periods, err := db.periods(game)
+		if err != nil {
+			return a, err
+		}
+		lines, err := db.boxScore(game)
+		if err != nil {
+			return a, err
+		}
+		ag := archiveGame{
+			ID:        game.ID,
+			Season:    game.Season.ID,
+			Date:      game.Date,
+			Title:     game.Title,
+			Venue:     game.Venue,
+			Status:    game.Status,
+			Time:      game.Time,
+			Home:      game.Home.ID,
+			HomeScore: game.HomeScore,
+			Away:      game.Away.ID,
+			AwayScore: game.AwayScore,
+			Resources: make([]archiveResource, len(resources)),
+		}
+		for _, period := range periods {
+			ag.Periods = append(ag.Periods, archivePeriod{period.Number, period.HomeScore, period.AwayScore})
+		}
+		for _, line := range lines {
+			ag.StatLines = append(ag.StatLines, archiveStatLine{line.Player.ID, line.ClubID, line.Values})
+		}
+		for j, resource := range resources {
+			ag.Resources[j] = archiveResource{resource.ID, resource.Title, resource.URL}
+		}
+		a.Games[i] = ag
+	}
+
+	return a, nil
+}
+
+// restoreArchive loads a into db, which must be empty, in a single
+// transaction. Every row keeps the ID it was exported with.
+func restoreArchive(db store, a archive) error {
+	if a.Version < 1 || a.Version > archiveVersion {
+		return fmt.Errorf("unsupported archive version %d, expected at most %d", a.Version, archiveVersion)
+	}
+
+	return db.transaction(func(tx store) error {
+		empty, err := tx.empty()
+		if err != nil {
+			return err
+		}
+		if !empty {
+			return errors.New("restore requires an empty database")
+		}
+
+		for _, sport := range a.Sports {
+			if a.Version < 3 {
+				sport.PeriodName, sport.OvertimeName = "Period", "OT"
+			}
+			s := Sport{sport.ID, sport.Name, sport.Periods, sport.PeriodName, sport.OvertimeName}
+			if err := tx.restoreSport(s); err != nil {
+				return fmt.Errorf("sport %d: %w", sport.ID, err)
+			}
+			for _, stat := range sport.Stats {
+				if err := tx.restoreStat(s, Stat{stat.ID, stat.Code, stat.Name}); err != nil {
+					return fmt.Errorf("sport %d stat %d: %w", sport.ID, stat.ID, err)
+				}
+			}
+		}
+
+		leagues := make(map[string]League)
+		for _, al := range a.Leagues {
+			league := League{Sport: al.Sport, Code: al.Code, Name: al.Name}
+			if err := tx.restoreLeague(league); err != nil {
+				return err
+			}
+			leagues[al.Code] = league
+		}
+
+		for _, club := range a.Clubs {
+			c := Club{club.ID, club.Iteration, club.Represents, club.Nickname}
+			if err := tx.restoreClub(c); err != nil {
+				return fmt.Errorf("club %d/%d: %w", club.ID, club.Iteration, err)
+			}
+		}
+
+		players := make(map[int]Player)
+		for _, ap := range a.Players {
+			player := Player{ap.ID, ap.Name}
+			if err := tx.restorePlayer(player); err != nil {
+				return fmt.Errorf("player %d: %w", ap.ID, err)
+			}
+			players[ap.ID] = player
+		}
+
+		activeSeasons := make(map[int]bool)
+		for _, al := range a.Leagues {
+			if al.ActiveSeason != 0 {
+				activeSeasons[al.ActiveSeason] = true
+			}
+			for _, ref := range al.ActiveClubs {
+				club := Club{ID: ref.ID, Iteration: ref.Iteration}
+				if err := tx.restoreActiveClub(leagues[al.Code], club); err != nil {
+					return fmt.Errorf("league %s club %d: %w", al.Code, ref.ID, err)
+				}
+			}
+		}
+
+		seasons := make(map[int]Season)
+		for _, as := range a.Seasons {
+			league, found := leagues[as.League]
+			if !found {
+				return fmt.Errorf("season %d: no league %s", as.ID, as.League)
+			}
+			season := Season{as.ID, league, as.Year, as.Type, as.Exhibition}
+			if err := tx.restoreSeason(season, activeSeasons[as.ID]); err != nil {
+				return fmt.Errorf("season %d: %w", as.ID, err)
+			}
+			for _, ref := range as.Clubs {
+				club := Club{ID: ref.ID, Iteration: ref.Iteration}
+				if err := tx.restoreSeasonClub(season, club); err != nil {
+					return fmt.Errorf("season %d club %d: %w", as.ID, ref.ID, err)
+				}
+			}
+			for _, ar := range as.Rosters {
+				for _, id := range ar.Players {
+					err := tx.addRosterPlayer(season, Club{ID: ar.Club}, players[id])
+					if err != nil {
+						return fmt.Errorf("season %d club %d player %d: %w", as.ID, ar.Club, id, err)
+					}
+				}
+			}
+			seasons[as.ID] = season
+		}
+
+		for _, ag := range a.Games {
+			season, found := seasons[ag.Season]
+			if !found {
+				return fmt.Errorf("game %d: no season %d", ag.ID, ag.Season)
+			}
+			if ag.Status == "" {
+				ag.Status = statusFinal
+			}
+			game := Game{
+				ID:        ag.ID,
+				Season:    season,
+				Date:      ag.Date,
+				Time:      ag.Time,
+				Status:    ag.Status,
+				Title:     ag.Title,
+				Venue:     ag.Venue,
+				Home:      Club{ID: ag.Home},
+				HomeScore: ag.HomeScore,
+				Away:      Club{ID: ag.Away},
+				AwayScore: ag.AwayScore,
+			}
+			if err := tx.restoreGame(game); err != nil {
+				return fmt.Errorf("game %d: %w", ag.ID, err)
+			}
+			if len(ag.Periods) > 0 {
+				periods := make([]Period, len(ag.Periods))
+				for i, ap := range ag.Periods {
+					periods[i] = Period{ap.Period, ap.HomeScore, ap.AwayScore}
+				}
+				if err := tx.setPeriods(game, periods); err != nil {
+					return fmt.Errorf("game %d: %w", ag.ID, err)
+				}
+			}
+			for _, al := range ag.StatLines {
+				line := StatLine{GameID: ag.ID, Player: players[al.Player], ClubID: al.Club, Values: al.Values}
+				if err := tx.setStatLine(game, line); err != nil {
+					return fmt.Errorf("game %d player %d: %w", ag.ID, al.Player, err)
+				}
+			}
+			for _, ar := range ag.Resources {
+				resource := Resource{ar.ID, ar.Title, ar.URL}
+				if err := tx.restoreResource(game, resource); err != nil {
+					return fmt.Errorf("game %d resource %d: %w", ag.ID, ar.ID, err)
+				}
+			}
+		}
+
+		return tx.resetIdentities()
+	})
+}
+
+func writeArchive(w io.Writer, a archive) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(a)
+}
+
+func readArchive(r io.Reader) (archive, error) {
+	var a archive
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&a)
+	return a, err
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/tdewolff/minify"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// mediaTypes are the media types minified, by extension. Assets of
+// any other type are used as they are.
+var mediaTypes = map[string]string{
+	".tmpl": "text/html",
+	".css":  "text/css",
+}
+
+// siteAssets are the page templates and static assets the site is
+// built from. The defaults are built into the binary, laid out like
+// web, and a file of the same name under the recap directory
+// overrides one of them. Every asset is minified as it is loaded.
+type siteAssets struct {
+	builtin   fs.FS
+	recapPath string
+	minifier  *minify.M
+}
+
+func newSiteAssets(builtin fs.FS, recapPath string) siteAssets {
+	return siteAssets{builtin: builtin, recapPath: recapPath, minifier: newMinifier()}
+}
+
+// templates returns the source of every page template, by name
+func (assets siteAssets) templates() (map[string]string, error) {
+	loaded, err := assets.load("templates", "*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	sources := make(map[string]string, len(loaded))
+	for name, data := range loaded {
+		sources[name] = string(data)
+	}
+	return sources, nil
+}
+
+// static returns the content of every static asset, by name
+func (assets siteAssets) static() (map[string][]byte, error) {
+	return assets.load("static", "*")
+}
+
+// load reads the files in dir matching pattern, built in or overridden
+func (assets siteAssets) load(dir, pattern string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+
+	builtin, err := fs.Glob(assets.builtin, path.Join(dir, pattern))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range builtin {
+		data, err := fs.ReadFile(assets.builtin, name)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Base(name)] = data
+	}
+
+	overrides, err := filepath.Glob(filepath.Join(assets.recapPath, dir, pattern))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range overrides {
+		if info, err := os.Stat(name); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.Base(name)] = data
+	}
+
+	for name, data := range files {
+		mediatype, found := mediaTypes[path.Ext(name)]
+		if !found {
+			continue
+		}
+		var minified bytes.Buffer
+		if err := assets.minifier.Minify(mediatype, &minified, bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		files[name] = minified.Bytes()
+	}
+	return files, nil
+}
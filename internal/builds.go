@@ -0,0 +1,245 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Generating the whole site renders into a new build under
+// RECAP_DIR/builds, which starts as a hard linked copy of the live
+// site so unchanged pages can still be skipped. Once it is complete
+// and valid, the www symlink is flipped to it in one rename, and
+// www.previous is pointed at the build it replaced for rollback. Only
+// those two builds are kept.
+
+const (
+	buildsDir       = "builds"
+	previousLink    = "www.previous"
+	buildTimeFormat = "20060102-150405.000000000"
+)
+
+// buildPaths locates the live site and its builds under recapPath
+type buildPaths struct {
+	root     string
+	www      string
+	builds   string
+	previous string
+}
+
+func newBuildPaths(recapPath string) buildPaths {
+	return buildPaths{
+		root:     recapPath,
+		www:      filepath.Join(recapPath, "www"),
+		builds:   filepath.Join(recapPath, buildsDir),
+		previous: filepath.Join(recapPath, previousLink),
+	}
+}
+
+// stage creates a new build seeded with hard links to the files of the
+// live site, returning its path
+func (paths buildPaths) stage() (string, error) {
+	if err := os.MkdirAll(paths.builds, 0755); err != nil {
+		return "", err
+	}
+	staging := filepath.Join(paths.builds, time.Now().UTC().Format(buildTimeFormat))
+	if err := os.Mkdir(staging, 0755); err != nil {
+		return "", err
+	}
+
+	live, err := filepath.EvalSymlinks(paths.www)
+	if errors.Is(err, fs.ErrNotExist) {
+		return staging, nil
+	} else if err != nil {
+		return "", err
+	}
+
+	err = filepath.WalkDir(live, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(live, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(staging, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if err := os.Link(path, target); err != nil {
+			return copyFile(path, target)
+		}
+		return nil
+	})
+	if err != nil {
+		os.RemoveAll(staging)
+		return "", err
+	}
+	return staging, nil
+}
+
+// copyFile copies the file at from to a new file at to, for file
+// systems that cannot hard link
+func copyFile(from, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// validate checks that staging holds the site index and every page in
+// m, each with its gzipped copy, before it goes live
+func (paths buildPaths) validate(staging string, m *manifest) error {
+	for _, sitePath := range append(m.pages(), indexPath()) {
+		path := filepath.Join(staging, sitePath)
+		for _, p := range []string{path, fmt.Sprintf("%s.gz", path)} {
+			info, err := os.Stat(p)
+			if err != nil {
+				return fmt.Errorf("invalid build %s: %w", staging, err)
+			}
+			if !info.Mode().IsRegular() {
+				return fmt.Errorf("invalid build %s: %s is not a file", staging, p)
+			}
+			if info.Size() == 0 {
+				return fmt.Errorf("invalid build %s: %s is empty", staging, p)
+			}
+		}
+	}
+	return nil
+}
+
+// publish points www at staging, keeps the build it replaces as the
+// previous build and removes any older builds
+func (paths buildPaths) publish(staging string) error {
+	current, err := paths.current()
+	if err != nil {
+		return err
+	}
+
+	if err = paths.flip(paths.www, staging); err != nil {
+		return err
+	}
+	if current != "" {
+		if err = paths.flip(paths.previous, current); err != nil {
+			return err
+		}
+	}
+	return paths.removeOldBuilds(staging, current)
+}
+
+// published reports whether www is a symlink to a build
+func (paths buildPaths) published() bool {
+	info, err := os.Lstat(paths.www)
+	return err == nil && info.Mode()&fs.ModeSymlink != 0
+}
+
+// current returns the build www points at. A www directory from before
+// builds were kept is moved into builds first, which is the one time www
+// is briefly missing.
+func (paths buildPaths) current() (string, error) {
+	info, err := os.Lstat(paths.www)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(paths.www)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(paths.root, target)
+		}
+		return target, nil
+	}
+
+	build := filepath.Join(paths.builds, info.ModTime().UTC().Format(buildTimeFormat))
+	if err := os.Rename(paths.www, build); err != nil {
+		return "", err
+	}
+	return build, nil
+}
+
+// flip atomically points the symlink at link to build, by renaming a
+// new symlink over it
+func (paths buildPaths) flip(link, build string) error {
+	target, err := filepath.Rel(paths.root, build)
+	if err != nil {
+		return err
+	}
+	tmp := fmt.Sprintf("%s.tmp", link)
+	os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	return os.Rename(tmp, link)
+}
+
+// removeOldBuilds deletes every build but keep
+func (paths buildPaths) removeOldBuilds(keep ...string) error {
+	entries, err := os.ReadDir(paths.builds)
+	if err != nil {
+		return err
+	}
+
+	kept := make(map[string]bool, len(keep))
+	for _, build := range keep {
+		kept[filepath.Clean(build)] = true
+	}
+	for _, entry := range entries {
+		build := filepath.Join(paths.builds, entry.Name())
+		if !kept[build] {
+			if err := os.RemoveAll(build); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// rollback points www back at the previous build, and www.previous at
+// the build it replaces, so a second rollback undoes the first
+func (paths buildPaths) rollback() (string, error) {
+	target, err := os.Readlink(paths.previous)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", errors.New("no previous build to roll back to")
+	} else if err != nil {
+		return "", err
+	}
+	previous := filepath.Join(paths.root, target)
+	if _, err := os.Stat(previous); err != nil {
+		return "", fmt.Errorf("previous build: %w", err)
+	}
+
+	current, err := paths.current()
+	if err != nil {
+		return "", err
+	}
+	if err = paths.flip(paths.www, previous); err != nil {
+		return "", err
+	}
+	if current != "" {
+		if err = paths.flip(paths.previous, current); err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimPrefix(target, buildsDir+string(filepath.Separator)), nil
+}
@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// liveIndex returns the index page of the build link points at, or ""
+// if there is none
+func liveIndex(t *testing.T, link string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(link, "index.html"))
+	if os.IsNotExist(err) {
+		return ""
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// writeBuildFile writes content to path the way pages are written,
+// replacing any file linked from another build
+func writeBuildFile(t *testing.T, path, content string) {
+	t.Helper()
+	file, err := createFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// publishIndex stages a build with index as its index page and
+// publishes it
+func publishIndex(t *testing.T, paths buildPaths, index string) string {
+	t.Helper()
+	staging, err := paths.stage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeBuildFile(t, filepath.Join(staging, "index.html"), index)
+	if err := paths.publish(staging); err != nil {
+		t.Fatal(err)
+	}
+	return staging
+}
+
+func TestPublishAndRollback(t *testing.T) {
+	paths := newBuildPaths(t.TempDir())
+	if paths.published() {
+		t.Fatal("published before the first build")
+	}
+	if _, err := paths.rollback(); err == nil {
+		t.Fatal("rolled back before anything was published")
+	}
+
+	steps := []struct {
+		publish  string // index page of a build to publish, or "" to roll back
+		www      string
+		previous string
+	}{
+		{publish: "one", www: "one"},
+		{publish: "two", www: "two", previous: "one"},
+		{publish: "three", www: "three", previous: "two"},
+		{www: "two", previous: "three"},
+		{www: "three", previous: "two"},
+		{publish: "four", www: "four", previous: "three"},
+	}
+	for i, step := range steps {
+		if step.publish != "" {
+			publishIndex(t, paths, step.publish)
+		} else if _, err := paths.rollback(); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+
+		if !paths.published() {
+			t.Errorf("step %d: www is not a published build", i)
+		}
+		if got := liveIndex(t, paths.www); got != step.www {
+			t.Errorf("step %d: www has %q, want %q", i, got, step.www)
+		}
+		if got := liveIndex(t, paths.previous); got != step.previous {
+			t.Errorf("step %d: www.previous has %q, want %q", i, got, step.previous)
+		}
+		builds, err := os.ReadDir(paths.builds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(builds) > 2 {
+			t.Errorf("step %d: kept %d builds, want at most 2", i, len(builds))
+		}
+	}
+}
+
+func TestStageLinksLivePages(t *testing.T) {
+	paths := newBuildPaths(t.TempDir())
+	live := publishIndex(t, paths, "live")
+
+	staging, err := paths.stage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.Stat(filepath.Join(live, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := os.Stat(filepath.Join(staging, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(before, after) {
+		t.Error("staged page is not a link to the live page")
+	}
+
+	// Replacing a staged page must leave the live one alone
+	writeBuildFile(t, filepath.Join(staging, "index.html"), "staged")
+	if got := liveIndex(t, paths.www); got != "live" {
+		t.Errorf("www has %q, want %q", got, "live")
+	}
+}
+
+func TestPublishReplacesWWWDirectory(t *testing.T) {
+	paths := newBuildPaths(t.TempDir())
+	writeBuildFile(t, filepath.Join(paths.www, "index.html"), "old")
+	if paths.published() {
+		t.Fatal("a www directory counts as a published build")
+	}
+	publishIndex(t, paths, "new")
+
+	if got := liveIndex(t, paths.www); got != "new" {
+		t.Errorf("www has %q, want %q", got, "new")
+	}
+	if got := liveIndex(t, paths.previous); got != "old" {
+		t.Errorf("www.previous has %q, want %q", got, "old")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		valid bool
+	}{
+		{"complete", map[string]string{
+			"index.html": "i", "index.html.gz": "i", "game.html": "g", "game.html.gz": "g"}, true},
+		{"page missing", map[string]string{
+			"index.html": "i", "index.html.gz": "i", "game.html.gz": "g"}, false},
+		{"gzipped page missing", map[string]string{
+			"index.html": "i", "index.html.gz": "i", "game.html": "g"}, false},
+		{"page empty", map[string]string{
+			"index.html": "i", "index.html.gz": "i", "game.html": "", "game.html.gz": "g"}, false},
+		{"index missing", map[string]string{
+			"game.html": "g", "game.html.gz": "g"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			paths := newBuildPaths(dir)
+			staging := filepath.Join(dir, "staging")
+			for name, content := range test.files {
+				writeBuildFile(t, filepath.Join(staging, name), content)
+			}
+			m := loadManifest(filepath.Join(dir, "manifest.json"), "v1")
+			m.Pages["game.html"] = manifestEntry{}
+
+			if err := paths.validate(staging, m); (err == nil) != test.valid {
+				t.Errorf("got error %v, want valid %v", err, test.valid)
+			}
+		})
+	}
+}
+
+func TestRollbackThenGenerate(t *testing.T) {
+	dir := t.TempDir()
+	db := newMemoryStore()
+	a, err := readArchive(strings.NewReader(testArchive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restoreArchive(db, a); err != nil {
+		t.Fatal(err)
+	}
+	cli := CLI{store: db, jobs: 1}
+	if err := cli.docGen.Initialize(dir, os.DirFS("../web")); err != nil {
+		t.Fatal(err)
+	}
+	generate := func() {
+		cli.generateSite()
+		if err := cli.docGen.saveManifest(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	game, err := db.game(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := filepath.Join(cli.docGen.builds.www, gamePath(game))
+
+	generate()
+	if _, err := os.Stat(page); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.deleteGame(game); err != nil {
+		t.Fatal(err)
+	}
+	generate()
+
+	// Rolling back brings back the build with the deleted game's page,
+	// which the next build must not keep
+	if err := cli.rollbackCommand(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(page); err != nil {
+		t.Fatalf("rolled back build: %v", err)
+	}
+	generate()
+
+	for _, file := range []string{page, page + ".gz"} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("%s kept after rolling back and generating", file)
+		}
+	}
+	for _, sitePath := range cli.docGen.manifest.pages() {
+		if _, err := os.Stat(filepath.Join(cli.docGen.builds.www, sitePath)); err != nil {
+			t.Errorf("manifest page %s: %v", sitePath, err)
+		}
+	}
+	if _, found := cli.docGen.manifest.Pages[gamePath(game)]; found {
+		t.Errorf("%s still in the manifest", gamePath(game))
+	}
+}
@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Calendars are iCalendar (RFC 5545) documents with one event per game,
+// written next to the pages of club and league seasons. Games without a
+// start time are all-day events; the rest start at a floating local time.
+
+func calendarPath(pagePath string) string {
+	return fmt.Sprintf("%s.ics", strings.TrimSuffix(pagePath, ".html"))
+}
+
+func seasonCalendarPath(season Season) string {
+	return fmt.Sprintf("/%s/%d/%s/schedule.ics",
+		season.League.Code,
+		season.Year,
+		season.Type)
+}
+
+// calendarText escapes a TEXT property value
+func calendarText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// calendarDate converts a YYYY-MM-DD game date to an iCalendar DATE
+func calendarDate(date string) string {
+	return strings.ReplaceAll(date, "-", "")
+}
+
+// calendarWriter writes content lines folded to 75 octets and ended
+// with CRLF, remembering the first error
+type calendarWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (cw *calendarWriter) line(name, value string) {
+	if cw.err != nil {
+		return
+	}
+
+	line := fmt.Sprintf("%s:%s", name, value)
+	var b strings.Builder
+	limit := 75
+	for len(line) > limit {
+		// Don't split a UTF-8 sequence across lines
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+
+		// Continuation lines start with a space
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+
+	_, cw.err = io.WriteString(cw.w, b.String())
+}
+
+// writeCalendar writes a calendar named name of games
+func writeCalendar(w io.Writer, name string, games []Game) error {
+	cw := &calendarWriter{w: w}
+	cw.line("BEGIN", "VCALENDAR")
+	cw.line("VERSION", "2.0")
+	cw.line("PRODID", "-//Recap//Recap//EN")
+	cw.line("CALSCALE", "GREGORIAN")
+	cw.line("X-WR-CALNAME", calendarText(fmt.Sprintf("Recap: %s", name)))
+
+	for _, game := range games {
+		summary := fmt.Sprintf("%s vs. %s", game.Home.Nickname, game.Away.Nickname)
+		if game.Title != "" {
+			summary = fmt.Sprintf("%s: %s", summary, game.Title)
+		}
+
+		cw.line("BEGIN", "VEVENT")
+		cw.line("UID", fmt.Sprintf("game-%d@recap", game.ID))
+		cw.line("DTSTAMP", fmt.Sprintf("%sT000000Z", calendarDate(game.Date)))
+		if game.Time != "" {
+			cw.line("DTSTART", fmt.Sprintf("%sT%s00",
+				calendarDate(game.Date), strings.ReplaceAll(game.Time, ":", "")))
+		} else {
+			cw.line("DTSTART;VALUE=DATE", calendarDate(game.Date))
+		}
+		if game.Status == statusCancelled {
+			cw.line("STATUS", "CANCELLED")
+		}
+		cw.line("SUMMARY", calendarText(summary))
+		if game.Venue != "" {
+			cw.line("LOCATION", calendarText(game.Venue))
+		}
+		cw.line("DESCRIPTION", calendarText(gameSummary(game)))
+		cw.line("END", "VEVENT")
+	}
+
+	cw.line("END", "VCALENDAR")
+	return cw.err
+}
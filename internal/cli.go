@@ -3,30 +3,163 @@ package internal
 import (
 	"bufio"
 	"database/sql"
+	"flag"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 )
 
 type CLI struct {
-	store  store
-	docGen documentGenerator
+	store      store
+	docGen     documentGenerator
+	migrations []migration
+
+	// jobs is how many pages are rendered at once when generating
+	// indices or the whole site, or one per CPU if not positive
+	jobs int
+	// pruneSite removes stale files after generating the whole site
+	pruneSite bool
 }
 
-func (cli *CLI) Initialize(database *sql.DB, recapDirectory string) {
-	cli.store = store{database}
+// Initialize prepares the CLI to use database, opened with the named
+// database/sql driver, the schema migrations for that driver found in
+// migrations, and the templates and output under recapDirectory
+func (cli *CLI) Initialize(driver string, database *sql.DB, migrations, web fs.FS, recapDirectory string) error {
+	var err error
+	if cli.store, err = newStore(driver, database); err != nil {
+		return err
+	}
+	if cli.migrations, err = loadMigrations(migrations, migrationDirs[driver]); err != nil {
+		return err
+	}
 	docGen := new(documentGenerator)
-	docGen.Initialize(recapDirectory)
+	if err = docGen.Initialize(recapDirectory, web); err != nil {
+		return err
+	}
 	cli.docGen = *docGen
+	return nil
+}
+
+// Start runs the subcommand named by args, or shows the interactive
+// menu when no command is given
+func (cli CLI) Start(args []string) {
+	fs := flag.NewFlagSet("recap", flag.ExitOnError)
+	fs.Usage = usage
+	dryRun := fs.Bool("dry-run", false, "report what would change without writing to the database or www")
+	fs.Parse(args)
+
+	if fs.Arg(0) != "migrate" {
+		if db, managed := cli.store.(migrator); managed {
+			if err := checkSchema(db, cli.migrations); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	if *dryRun {
+		if err := cli.startDryRun(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if fs.NArg() == 0 {
+		cli.menu()
+	} else {
+		cli.run(fs.Args())
+	}
+
+	if err := cli.docGen.saveManifest(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
 }
 
-func (cli CLI) Start() {
-	actions := []string{"Add game", "Edit Game", "Generate Sidebars", "Generate Indices", "Generate Site"}
-	funcs := []func(){cli.addGame, cli.editGame, cli.generateSidebars, cli.generateIndices, cli.generateSite}
+// startDryRun copies the database into memory and stops the document
+// generator from writing, so that nothing the CLI does is saved
+func (cli *CLI) startDryRun() error {
+	a, err := exportArchive(cli.store)
+	if err != nil {
+		return err
+	}
+
+	memory := newMemoryStore()
+	if err = restoreArchive(memory, a); err != nil {
+		return err
+	}
+
+	cli.store = memory
+	cli.docGen.dryRun = true
+	fmt.Fprintln(os.Stderr, "Dry run: changes will not be saved")
+	return nil
+}
+
+func (cli CLI) menu() {
+	actions := []string{"Add game", "Record Result", "Edit Game", "Delete Game", "Enter Box Score", "Manage Leagues and Clubs", "Generate Indices", "Generate Site"}
+	funcs := []func(){cli.addGame, cli.recordResult, cli.editGame, cli.deleteGame, cli.enterBoxScore, cli.manage, cli.generateIndices, cli.generateSite}
 	funcs[promptList(actions)]()
 }
 
+// dirtyClub identifies a club index page that must be regenerated
+type dirtyClub struct {
+	club   Club
+	season Season
+}
+
+// dirtyMatchup identifies a matchup page that must be regenerated,
+// keyed by club ID so it covers every iteration of both clubs
+type dirtyMatchup struct {
+	league League
+	a      int
+	b      int
+}
+
+// dirtyPages collects the pages that must be regenerated after
+// games change
+type dirtyPages struct {
+	leagues  map[League]bool
+	seasons  map[Season]bool
+	clubs    map[dirtyClub]bool
+	matchups map[dirtyMatchup]bool
+	players  map[Player]bool
+}
+
+func newDirtyPages() dirtyPages {
+	return dirtyPages{
+		leagues:  make(map[League]bool),
+		seasons:  make(map[Season]bool),
+		clubs:    make(map[dirtyClub]bool),
+		matchups: make(map[dirtyMatchup]bool),
+		players:  make(map[Player]bool),
+	}
+}
+
+// addPlayers marks the pages of players, which list the games they
+// appeared in
+func (d dirtyPages) addPlayers(players []Player) {
+	for _, player := range players {
+		d.players[player] = true
+	}
+}
+
+// addGame marks every page that lists game
+func (d dirtyPages) addGame(game Game) {
+	d.leagues[game.Season.League] = true
+	d.seasons[game.Season] = true
+	d.clubs[dirtyClub{game.Home, game.Season}] = true
+	d.clubs[dirtyClub{game.Away, game.Season}] = true
+	d.matchups[newDirtyMatchup(game)] = true
+}
+
+func newDirtyMatchup(game Game) dirtyMatchup {
+	a, b := matchupClubs(game)
+	return dirtyMatchup{game.Season.League, a.ID, b.ID}
+}
+
 func promptInt(name string) (val int) {
 	scanner := bufio.NewScanner(os.Stdin)
 	for {
@@ -95,6 +228,57 @@ func promptDate() string {
 	return date
 }
 
+// promptTime asks for a time of day, which may be left empty
+func promptTime() string {
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Printf("Enter time (HH:MM, optional): ")
+		scanner.Scan()
+		time := scanner.Text()
+		if time == "" || validTime(time) == nil {
+			return time
+		}
+	}
+}
+
+// promptPeriods asks for the scores of each period of a game of sport,
+// offering periods past regulation until one is declined
+func promptPeriods(sport Sport) []Period {
+	periods := make([]Period, 0, sport.Periods)
+	for n := 1; n <= sport.Periods || promptBool(fmt.Sprintf("Add %s", periodTitle(sport, n))); n++ {
+		name := periodTitle(sport, n)
+		periods = append(periods, Period{
+			Number:    n,
+			HomeScore: promptInt(fmt.Sprintf("%s home score", name)),
+			AwayScore: promptInt(fmt.Sprintf("%s away score", name)),
+		})
+	}
+	return periods
+}
+
+// promptScores asks for the final score of a game in season, entered
+// period by period if its sport keeps line scores and one is at hand
+func (cli CLI) promptScores(season Season) (int, int, []Period) {
+	sport, err := cli.findSport(season.League.Sport)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	if sport.Periods > 0 && promptBool("Enter the line score") {
+		periods := promptPeriods(sport)
+		homeScore, awayScore := periodTotals(periods)
+		fmt.Println("Final score:", homeScore, "-", awayScore)
+		return homeScore, awayScore, periods
+	}
+	return promptInt("home score"), promptInt("away score"), nil
+}
+
+func promptStatus(statuses []string) string {
+	fmt.Println("Select status:")
+	return statuses[promptList(statuses)]
+}
+
 func (cli CLI) promptLeagues() League {
 	leagues, err := cli.store.leagues()
 	if err != nil {
@@ -159,14 +343,94 @@ func (cli CLI) promptClubs(season Season) Club {
 	return clubs[promptList(clubsStr)]
 }
 
-func (cli CLI) generateGamePage(game Game) {
+// writeGamePage writes the page of game with its resources and line score
+func (cli CLI) writeGamePage(game Game) error {
 	resources, err := cli.store.resources(game)
 	if err != nil {
+		return err
+	}
+	return cli.renderGamePage(game, resources)
+}
+
+// renderGamePage writes the page of game, whose resources are already
+// loaded
+func (cli CLI) renderGamePage(game Game, resources []Resource) error {
+	sport, err := cli.findSport(game.Season.League.Sport)
+	if err != nil {
+		return err
+	}
+	periods, err := cli.store.periods(game)
+	if err != nil {
+		return err
+	}
+	stats, err := cli.store.stats(sport)
+	if err != nil {
+		return err
+	}
+	lines, err := cli.store.boxScore(game)
+	if err != nil {
+		return err
+	}
+
+	return cli.docGen.gamePage(game, resources, newLineScore(sport, periods),
+		newBoxScores(game, stats, lines))
+}
+
+// gamePlayers returns the players in the box score of game
+func (cli CLI) gamePlayers(game Game) ([]Player, error) {
+	lines, err := cli.store.boxScore(game)
+	if err != nil {
+		return nil, err
+	}
+	players := make([]Player, len(lines))
+	for i, line := range lines {
+		players[i] = line.Player
+	}
+	return players, nil
+}
+
+// writePlayerPage writes the page of player with every game they
+// appeared in
+func (cli CLI) writePlayerPage(player Player) error {
+	lines, err := cli.store.playerLines(player)
+	if err != nil {
+		return err
+	}
+
+	var games []Game
+	if len(lines) > 0 {
+		filter := gameFilter{IDs: make([]int, len(lines))}
+		for i, line := range lines {
+			filter.IDs[i] = line.GameID
+		}
+		if games, err = cli.store.games(filter); err != nil {
+			return err
+		}
+	}
+
+	sports, err := cli.store.sports()
+	if err != nil {
+		return err
+	}
+	stats := make(map[string][]Stat, len(sports))
+	for _, sport := range sports {
+		if stats[sport.Name], err = cli.store.stats(sport); err != nil {
+			return err
+		}
+	}
+
+	return cli.docGen.playerPage(player, newPlayerSeasons(games, lines, stats))
+}
+
+func (cli CLI) generatePlayerPage(player Player) {
+	if err := cli.writePlayerPage(player); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
+}
 
-	if err = cli.docGen.gamePage(game, resources); err != nil {
+func (cli CLI) generateGamePage(game Game) {
+	if err := cli.writeGamePage(game); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
@@ -180,49 +444,185 @@ func (cli CLI) generateClubIndex(club Club, season Season) {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
-	if err = cli.docGen.clubIndex(club, season, games); err != nil {
+	seasons, err := cli.clubSeasons(club, season.League)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if err = cli.writeClubIndex(club, season, games, seasons); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 	}
 }
 
-func (cli CLI) generateLeagueIndex(league League) {
-	filter := gameFilter{Leagues: []League{league}}
-	filter.SetLimit(20)
-	var games []Game
-	var err error
-	if games, err = cli.store.games(filter); err != nil {
+// writeClubIndex writes the index page, feed and calendar of the games
+// club played in season. seasons are those the club played in the
+// league, for the sidebar.
+func (cli CLI) writeClubIndex(club Club, season Season, games []Game, seasons []Season) error {
+	if err := cli.docGen.clubIndex(club, season, games, seasons); err != nil {
+		return err
+	}
+	if err := cli.docGen.clubFeed(club, season, games); err != nil {
+		return err
+	}
+	return cli.docGen.clubCalendar(club, season, games)
+}
+
+// clubSeasons returns every season club played in league
+func (cli CLI) clubSeasons(club Club, league League) ([]Season, error) {
+	filter := seasonFilter{}
+	filter.SetClub(club)
+	filter.SetLeague(league)
+	return cli.store.seasons(filter)
+}
+
+// generateSeason writes the standings and calendar of season
+func (cli CLI) generateSeason(season Season) {
+	clubs, err := cli.store.clubsBySeason(season)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
-	if err = cli.docGen.leagueIndex(league, games); err != nil {
+
+	games, err := cli.store.games(gameFilter{Seasons: []Season{season}})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	filter := seasonFilter{}
+	filter.SetLeague(season.League)
+	seasons, err := cli.store.seasons(filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	if err = cli.writeSeason(season, clubs, games, seasons); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
 }
 
-func (cli CLI) generateIndex() {
-	filter := gameFilter{}
-	filter.SetLimit(20)
-	var games []Game
-	var err error
-	if games, err = cli.store.games(filter); err != nil {
+// writeSeason writes the standings and calendar of season from its
+// clubs and games. seasons are those of the league, for the sidebar.
+func (cli CLI) writeSeason(season Season, clubs []Club, games []Game, seasons []Season) error {
+	if err := cli.docGen.standingsPage(season, standings(clubs, games), seasons); err != nil {
+		return err
+	}
+	return cli.docGen.seasonCalendar(season, games)
+}
+
+// generateMatchup rewrites the page of every game between the clubs
+// of matchup, or removes it if they no longer have any
+func (cli CLI) generateMatchup(matchup dirtyMatchup) {
+	if err := cli.writeMatchup(matchup); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
-	if err = cli.docGen.index(games); err != nil {
+}
+
+func (cli CLI) writeMatchup(matchup dirtyMatchup) error {
+	a, b := Club{ID: matchup.a}, Club{ID: matchup.b}
+	filter := gameFilter{Clubs: []Club{a, b}, Leagues: []League{matchup.league}, AllClubs: true}
+	games, err := cli.store.games(filter)
+	if err != nil {
+		return err
+	}
+
+	if len(games) == 0 {
+		return cli.docGen.removeMatchupPage(matchup.league, a, b)
+	}
+	return cli.docGen.matchupPage(matchup.league, newMatchup(games))
+}
+
+func (cli CLI) generateLeagueIndex(league League) {
+	if err := cli.writeLeagueIndex(league); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
 }
 
-func (cli CLI) addGame() {
+func (cli CLI) writeLeagueIndex(league League) error {
+	filter := gameFilter{Leagues: []League{league}}
+	filter.SetLimit(20)
+	games, err := cli.store.games(filter)
+	if err != nil {
+		return err
+	}
+
+	season, err := cli.store.activeSeason(league)
+	if err != nil {
+		return err
+	}
+	clubs, err := cli.store.clubsByLeague(league, true)
+	if err != nil {
+		return err
+	}
+	seasonsFilter := seasonFilter{}
+	seasonsFilter.SetLeague(league)
+	seasons, err := cli.store.seasons(seasonsFilter)
+	if err != nil {
+		return err
+	}
+
+	if err = cli.docGen.leagueIndex(league, games, season, clubs, seasons); err != nil {
+		return err
+	}
+	return cli.docGen.leagueFeed(league, games)
+}
+
+func (cli CLI) generateIndex() {
+	if err := cli.writeIndex(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (cli CLI) writeIndex() error {
+	filter := gameFilter{}
+	filter.SetLimit(20)
+	games, err := cli.store.games(filter)
+	if err != nil {
+		return err
+	}
+	leagues, err := cli.store.leagues()
+	if err != nil {
+		return err
+	}
+	if err = cli.docGen.index(games, leagues); err != nil {
+		return err
+	}
+	return cli.docGen.indexFeed(games)
+}
+
+// regenerate rewrites the dirty club and league indices, season
+// standings and matchups along with the site index
+func (cli CLI) regenerate(dirty dirtyPages) {
+	for club := range dirty.clubs {
+		cli.generateClubIndex(club.club, club.season)
+	}
+
+	for season := range dirty.seasons {
+		cli.generateSeason(season)
+	}
+
+	for matchup := range dirty.matchups {
+		cli.generateMatchup(matchup)
+	}
+
+	for league := range dirty.leagues {
+		cli.generateLeagueIndex(league)
+	}
 
-	type dirtyClub struct {
-		club   Club
-		season Season
+	for player := range dirty.players {
+		cli.generatePlayerPage(player)
 	}
-	dirtyLeagues := make(map[League]bool)
-	dirtyClubs := make(map[dirtyClub]bool)
+
+	cli.generateIndex()
+}
+
+func (cli CLI) addGame() {
+	dirty := newDirtyPages()
 
 	done := false
 	for !done {
@@ -233,8 +633,13 @@ func (cli CLI) addGame() {
 		fmt.Println("Select away team:")
 		away := cli.promptClubs(season)
 		date := promptDate()
-		homeScore := promptInt("home score")
-		awayScore := promptInt("away score")
+		time := promptTime()
+		status := promptStatus(gameStatuses)
+		homeScore, awayScore := 0, 0
+		var periods []Period
+		if status == statusFinal || status == statusForfeit {
+			homeScore, awayScore, periods = cli.promptScores(season)
+		}
 		title := promptString("title", 0, 128)
 		venue := promptString("venue", 0, 128)
 
@@ -245,58 +650,170 @@ func (cli CLI) addGame() {
 			away,
 			awayScore,
 			title,
-			venue)
+			venue,
+			status,
+			time)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			os.Exit(1)
 		}
 
-		resources := make([]Resource, 0)
+		if len(periods) > 0 {
+			if err := cli.store.setPeriods(game, periods); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+		}
+
 		doneResources := !promptBool("Add a resource")
 		for !doneResources {
 			title := promptString("title", 1, 128)
 			url := promptString("url", 1, 256)
-			resource, err := cli.store.createResource(game, title, url)
-			if err != nil {
+			if _, err := cli.store.createResource(game, title, url); err != nil {
 				fmt.Fprintf(os.Stderr, "%v\n", err)
 				os.Exit(1)
-			} else {
-				resources = append(resources, resource)
 			}
 			doneResources = !promptBool("Add a resource")
 		}
 
-		if err := cli.docGen.gamePage(game, resources); err != nil {
+		cli.generateGamePage(game)
+
+		dirty.addGame(game)
+
+		done = !promptBool("Add another game")
+	}
+
+	cli.regenerate(dirty)
+}
+
+// recordResult enters the scores of games that were scheduled
+func (cli CLI) recordResult() {
+	dirty := newDirtyPages()
+
+	done := false
+	for !done {
+		gameID := promptInt("game ID")
+		game, err := cli.store.game(gameID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("Game:", gameID)
+		fmt.Println("Home:", game.Home.Represents, game.Home.Nickname)
+		fmt.Println("Away:", game.Away.Represents, game.Away.Nickname)
+		fmt.Println("Date:", game.Date)
+		fmt.Println("Status:", game.Status)
+
+		edit := gameEdit{}
+		homeScore, awayScore, periods := cli.promptScores(game.Season)
+		edit.SetHomeScore(homeScore)
+		edit.SetAwayScore(awayScore)
+		if periods != nil {
+			edit.SetPeriods(periods)
+		}
+		edit.SetStatus(promptStatus([]string{statusFinal, statusForfeit}))
+
+		players, err := cli.gamePlayers(game)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			os.Exit(1)
 		}
+		dirty.addPlayers(players)
 
-		dirtyLeagues[league] = true
-		dirtyClubs[dirtyClub{home, season}] = true
-		dirtyClubs[dirtyClub{away, season}] = true
+		if game, err = cli.store.editGame(game, edit); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		cli.generateGamePage(game)
+		dirty.addGame(game)
 
-		done = !promptBool("Add another game")
+		done = !promptBool("Record another result")
 	}
 
-	for club := range dirtyClubs {
-		cli.generateClubIndex(club.club, club.season)
+	cli.regenerate(dirty)
+}
+
+// enterBoxScore enters the stat lines of players from the rosters of
+// a game's clubs
+func (cli CLI) enterBoxScore() {
+	dirty := newDirtyPages()
+
+	gameID := promptInt("game ID")
+	game, err := cli.store.game(gameID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	sport, err := cli.findSport(game.Season.League.Sport)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	stats, err := cli.store.stats(sport)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
 	}
 
-	for league := range dirtyLeagues {
-		cli.generateLeagueIndex(league)
+	fmt.Println("Game:", gameID)
+	fmt.Println("Home:", game.Home.Represents, game.Home.Nickname)
+	fmt.Println("Away:", game.Away.Represents, game.Away.Nickname)
+	fmt.Println("Date:", game.Date)
+
+	done := false
+	for !done {
+		clubs := []Club{game.Home, game.Away}
+		fmt.Println("Select team:")
+		club := clubs[promptList([]string{clubName(game.Home), clubName(game.Away)})]
+
+		roster, err := cli.store.roster(game.Season, club)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if len(roster) == 0 {
+			fmt.Println("No players on the roster")
+		} else {
+			rosterStr := make([]string, len(roster))
+			for i, player := range roster {
+				rosterStr[i] = player.Name
+			}
+			fmt.Println("Select player:")
+			player := roster[promptList(rosterStr)]
+
+			line := StatLine{GameID: game.ID, Player: player, ClubID: club.ID, Values: make(map[int]int)}
+			for _, stat := range stats {
+				line.Values[stat.ID] = promptInt(stat.Name)
+			}
+			if err := cli.store.setStatLine(game, line); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			dirty.addPlayers([]Player{player})
+		}
+
+		done = !promptBool("Enter another player")
 	}
 
-	cli.generateIndex()
+	cli.generateGamePage(game)
+	for player := range dirty.players {
+		cli.generatePlayerPage(player)
+	}
 }
 
-func (cli CLI) editGame() {
-
-	type dirtyClub struct {
-		club   Club
-		season Season
+// containsResource reports whether resources includes resource
+func containsResource(resources []Resource, resource Resource) bool {
+	for _, r := range resources {
+		if r.ID == resource.ID {
+			return true
+		}
 	}
-	dirtyLeagues := make(map[League]bool)
-	dirtyClubs := make(map[dirtyClub]bool)
+	return false
+}
+
+func (cli CLI) editGame() {
+	dirty := newDirtyPages()
 
 	done := false
 	for !done {
@@ -307,23 +824,23 @@ func (cli CLI) editGame() {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			os.Exit(1)
 		}
-		var resources []Resource
-		if resources, err = cli.store.resources(game); err != nil {
-			fmt.Fprintf(os.Stderr, "%v\n", err)
-			os.Exit(1)
-		}
-
 		fmt.Println("Game:", gameID)
 		fmt.Println("Home:", game.Home.Represents, game.Home.Nickname)
 		fmt.Println("Away:", game.Away.Represents, game.Away.Nickname)
 		fmt.Println("Date:", game.Date)
+		fmt.Println("Time:", game.Time)
+		fmt.Println("Status:", game.Status)
 		fmt.Println("Title:", game.Title)
 		fmt.Println("Venue:", game.Venue)
 		fmt.Println("Home Score:", game.HomeScore)
 		fmt.Println("Away Score:", game.AwayScore)
 
 		edit := gameEdit{}
-		fields := []string{"Date", "Title", "Venue", "Home Score", "Away Score", "Resources"}
+		// Resource changes are made in the same transaction as the
+		// edit, so a rejected edit changes nothing
+		var added, removed []Resource
+		fields := []string{"Date", "Time", "Status", "Title", "Venue", "Home Score", "Away Score",
+			"Line Score", "Season", "Home Team", "Away Team", "Swap Home/Away", "Resources"}
 
 		doneEditing := false
 		for !doneEditing {
@@ -332,6 +849,10 @@ func (cli CLI) editGame() {
 			switch field {
 			case "Date":
 				edit.SetDate(promptDate())
+			case "Time":
+				edit.SetTime(promptTime())
+			case "Status":
+				edit.SetStatus(promptStatus(gameStatuses))
 			case "Title":
 				edit.SetTitle(promptString("title", 0, 128))
 			case "Venue":
@@ -340,23 +861,58 @@ func (cli CLI) editGame() {
 				edit.SetHomeScore(promptInt("home score"))
 			case "Away Score":
 				edit.SetAwayScore(promptInt("away score"))
+			case "Line Score":
+				if promptBool("Remove the line score") {
+					edit.SetPeriods(nil)
+					break
+				}
+				sport, err := cli.findSport(game.Season.League.Sport)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "%v\n", err)
+					os.Exit(1)
+				}
+				periods := promptPeriods(sport)
+				homeScore, awayScore := periodTotals(periods)
+				edit.SetPeriods(periods)
+				edit.SetHomeScore(homeScore)
+				edit.SetAwayScore(awayScore)
+				fmt.Println("Final score:", homeScore, "-", awayScore)
+			case "Season":
+				edit.SetSeason(cli.promptSeasons(game.Season.League))
+			case "Home Team", "Away Team":
+				season := game.Season
+				if s, set := edit.Season(); set {
+					season = s
+				}
+				if field == "Home Team" {
+					fmt.Println("Select home team:")
+					edit.SetHome(cli.promptClubs(season))
+				} else {
+					fmt.Println("Select away team:")
+					edit.SetAway(cli.promptClubs(season))
+				}
+			case "Swap Home/Away":
+				edit.SwapHomeAway()
+				fmt.Println("Home and away swapped")
 			case "Resources":
 				actions := []string{"Add Resource", "Delete Resource"}
 				switch actions[promptList(actions)] {
 				case "Add Resource":
 					title := promptString("title", 1, 128)
 					url := promptString("url", 1, 256)
-					_, err := cli.store.createResource(game, title, url)
-					if err != nil {
-						fmt.Fprintf(os.Stderr, "%v\n", err)
-						os.Exit(1)
-					}
+					added = append(added, Resource{Title: title, URL: url})
 				case "Delete Resource":
-					resources, err := cli.store.resources(game)
+					stored, err := cli.store.resources(game)
 					if err != nil {
 						fmt.Fprintf(os.Stderr, "%v\n", err)
 						os.Exit(1)
 					}
+					var resources []Resource
+					for _, resource := range stored {
+						if !containsResource(removed, resource) {
+							resources = append(resources, resource)
+						}
+					}
 					if len(resources) == 0 {
 						fmt.Println("No resources to delete")
 						break
@@ -367,149 +923,337 @@ func (cli CLI) editGame() {
 					}
 
 					fmt.Println("Select resource to delete:")
-					err = cli.store.deleteResource(resources[promptList(resourcesStr)])
-					if err != nil {
-						fmt.Fprintf(os.Stderr, "%v\n", err)
-						os.Exit(1)
-					}
+					removed = append(removed, resources[promptList(resourcesStr)])
 				}
 			}
 			doneEditing = !promptBool("Continue editing")
 		}
 
-		game, err = cli.store.editGame(game, edit)
+		// Players stay in the box score unless their club is replaced,
+		// so take them before the edit
+		players, err := cli.gamePlayers(game)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			os.Exit(1)
 		}
+		dirty.addPlayers(players)
 
-		resources, err = cli.store.resources(game)
+		original := game
+		err = cli.store.transaction(func(tx store) error {
+			for _, resource := range removed {
+				if err := tx.deleteResource(resource); err != nil {
+					return err
+				}
+			}
+			for _, r := range added {
+				if _, err := tx.createResource(original, r.Title, r.URL); err != nil {
+					return err
+				}
+			}
+			var err error
+			game, err = tx.editGame(original, edit)
+			return err
+		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			os.Exit(1)
 		}
 
-		if err = cli.docGen.gamePage(game, resources); err != nil {
-			fmt.Fprintf(os.Stderr, "%v\n", err)
-			os.Exit(1)
+		if gamePath(original) != gamePath(game) {
+			if err = cli.docGen.removeGamePage(original); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
 		}
 
-		dirtyLeagues[game.Season.League] = true
-		dirtyClubs[dirtyClub{game.Home, game.Season}] = true
-		dirtyClubs[dirtyClub{game.Away, game.Season}] = true
-
-		done = !promptBool("Edit another game")
-	}
+		cli.generateGamePage(game)
 
-	for club := range dirtyClubs {
-		cli.generateClubIndex(club.club, club.season)
-	}
+		dirty.addGame(game)
+		dirty.addGame(original)
 
-	for league := range dirtyLeagues {
-		cli.generateLeagueIndex(league)
+		done = !promptBool("Edit another game")
 	}
 
-	cli.generateIndex()
+	cli.regenerate(dirty)
 }
 
-func (cli CLI) generateSidebars() {
-	leagues, err := cli.store.leagues()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
-		os.Exit(1)
-	}
+func (cli CLI) deleteGame() {
+	dirty := newDirtyPages()
 
-	if err = cli.docGen.indexSidebar(leagues); err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
-		os.Exit(1)
-	}
-
-	for _, league := range leagues {
-		season, err := cli.store.activeSeason(league)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "%v\n", err)
-			os.Exit(1)
-		}
-
-		clubs, err := cli.store.clubsByLeague(league, true)
+	done := false
+	for !done {
+		gameID := promptInt("game ID")
+		game, err := cli.store.game(gameID)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			os.Exit(1)
 		}
 
-		if err = cli.docGen.leagueSidebar(league, season, clubs); err != nil {
-			fmt.Fprintf(os.Stderr, "%v\n", err)
-			os.Exit(1)
-		}
-
-		clubs, err = cli.store.clubsByLeague(league, false)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "%v\n", err)
-			os.Exit(1)
-		}
+		fmt.Println("Game:", gameID)
+		fmt.Println("Home:", game.Home.Represents, game.Home.Nickname)
+		fmt.Println("Away:", game.Away.Represents, game.Away.Nickname)
+		fmt.Println("Date:", game.Date)
+		fmt.Println("Score:", game.HomeScore, "-", game.AwayScore)
 
-		for _, club := range clubs {
-			filter := seasonFilter{}
-			filter.SetClub(club)
-			filter.SetLeague(league)
-			seasons, err := cli.store.seasons(filter)
+		if promptBool("Delete this game") {
+			players, err := cli.gamePlayers(game)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "%v\n", err)
 				os.Exit(1)
 			}
-			if err = cli.docGen.clubSidebar(club, seasons, league); err != nil {
+			dirty.addPlayers(players)
+
+			if err = cli.removeGame(game); err != nil {
 				fmt.Fprintf(os.Stderr, "%v\n", err)
 				os.Exit(1)
 			}
+
+			dirty.addGame(game)
 		}
+
+		done = !promptBool("Delete another game")
+	}
+
+	if len(dirty.leagues) > 0 {
+		cli.regenerate(dirty)
 	}
 }
 
+// removeGame deletes game from the store along with its page
+func (cli CLI) removeGame(game Game) error {
+	if err := cli.store.deleteGame(game); err != nil {
+		return err
+	}
+	return cli.docGen.removeGamePage(game)
+}
+
 func (cli CLI) generateIndices() {
-	leagues, err := cli.store.leagues()
-	if err != nil {
+	pool := newRenderPool(cli.jobs)
+	queueErr := cli.queueIndices(pool)
+	if err := pool.wait(); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
+	if queueErr != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", queueErr)
+		os.Exit(1)
+	}
+}
+
+// queueIndices loads the games of each season at once and queues the
+// club indices and standings of every season, the league indices and
+// the site index on pool
+func (cli CLI) queueIndices(pool *renderPool) error {
+	leagues, err := cli.store.leagues()
+	if err != nil {
+		return err
+	}
 
 	for _, league := range leagues {
 		filter := seasonFilter{}
 		filter.SetLeague(league)
 		seasons, err := cli.store.seasons(filter)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%v\n", err)
-			os.Exit(1)
+			return err
 		}
 
+		// The clubs of each season, and the seasons of each club for the
+		// sidebars of its indices
+		seasonClubs := make(map[int][]Club)
+		clubSeasons := make(map[int][]Season)
 		for _, season := range seasons {
 			clubs, err := cli.store.clubsBySeason(season)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "%v\n", err)
-				os.Exit(1)
+				return err
+			}
+			seasonClubs[season.ID] = clubs
+			for _, club := range clubs {
+				clubSeasons[club.ID] = append(clubSeasons[club.ID], season)
+			}
+		}
+
+		for _, season := range seasons {
+			clubs := seasonClubs[season.ID]
+			games, err := cli.store.games(gameFilter{Seasons: []Season{season}})
+			if err != nil {
+				return err
 			}
 
 			for _, club := range clubs {
-				cli.generateClubIndex(club, season)
+				club, season, clubGames, seasons := club, season, clubGames(games, club), clubSeasons[club.ID]
+				pool.submit(func() error { return cli.writeClubIndex(club, season, clubGames, seasons) })
 			}
+
+			season, seasons := season, seasons
+			pool.submit(func() error { return cli.writeSeason(season, clubs, games, seasons) })
 		}
 
-		cli.generateLeagueIndex(league)
+		league := league
+		pool.submit(func() error { return cli.writeLeagueIndex(league) })
 	}
 
-	cli.generateIndex()
+	pool.submit(cli.writeIndex)
+	return nil
+}
+
+// clubGames picks the games club played out of games, keeping their order
+func clubGames(games []Game, club Club) []Game {
+	var played []Game
+	for _, game := range games {
+		if game.Home.ID == club.ID || game.Away.ID == club.ID {
+			played = append(played, game)
+		}
+	}
+	return played
 }
 
 func (cli CLI) generateSite() {
-	games, err := cli.store.games(gameFilter{})
-	if err != nil {
+	// Write a new build so the live site is untouched until every page
+	// has been written
+	var staging string
+	if !cli.docGen.dryRun {
+		var err error
+		if staging, err = cli.docGen.builds.stage(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		cli.docGen.staticPath = staging
+	}
+
+	// fail removes the unfinished build before exiting
+	fail := func(err error) {
+		if staging != "" {
+			os.RemoveAll(staging)
+		}
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
 
-	for _, game := range games {
-		cli.generateGamePage(game)
+	if err := cli.writeSite(); err != nil {
+		fail(err)
+	}
+
+	written, skipped, removed := cli.docGen.manifest.counts()
+	pruned := 0
+	if cli.pruneSite {
+		var err error
+		if pruned, err = cli.prune(cli.docGen.staticPath, cli.docGen.dryRun); err != nil {
+			fail(err)
+		}
+	}
+
+	if cli.docGen.dryRun {
+		fmt.Printf("Would write %d pages, skip %d unchanged and remove %d\n", written, skipped, removed)
+		if cli.pruneSite {
+			fmt.Printf("Would prune %d stale files\n", pruned)
+		}
+		return
+	}
+	fmt.Printf("Wrote %d pages, skipped %d unchanged and removed %d\n", written, skipped, removed)
+	if cli.pruneSite {
+		fmt.Printf("Pruned %d stale files\n", pruned)
+	}
+
+	// A build identical to the live one is not published, so the
+	// previous build is kept to roll back to
+	if !cli.docGen.manifest.changed() && pruned == 0 && cli.docGen.builds.published() {
+		os.RemoveAll(staging)
+		fmt.Printf("Site unchanged, nothing published\n")
+		return
+	}
+
+	if err := cli.docGen.builds.validate(staging, cli.docGen.manifest); err != nil {
+		fail(err)
+	}
+	// staging may already be live if publishing fails, so it is kept
+	if err := cli.docGen.builds.publish(staging); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Published build %s\n", filepath.Base(staging))
+}
+
+// pageKind is a set of the kinds of page written from each template
+type pageKind int
+
+const (
+	gamePages pageKind = 1 << iota
+	matchupPages
+	playerPages
+	indexPages
+	staticAssets
+	allPages = gamePages | matchupPages | playerPages | indexPages | staticAssets
+)
+
+// writeSite writes every page of the site under the
+// generator's site root, skipping unchanged pages and removing those of
+// records that no longer exist
+func (cli CLI) writeSite() error {
+	cli.docGen.manifest.startRun()
+	if err := cli.writePages(allPages); err != nil {
+		return err
+	}
+	return cli.docGen.removeStalePages()
+}
+
+// writePages writes every page of the given kinds, skipping unchanged
+// pages
+func (cli CLI) writePages(kinds pageKind) error {
+	var games []Game
+	var err error
+	if kinds&(gamePages|matchupPages) != 0 {
+		if games, err = cli.store.games(gameFilter{}); err != nil {
+			return err
+		}
 	}
 
-	cli.generateSidebars()
-	cli.generateIndices()
+	pool := newRenderPool(cli.jobs)
+	if kinds&gamePages != 0 {
+		resources, err := cli.store.resourcesByGame(games)
+		if err != nil {
+			pool.wait()
+			return err
+		}
+		for _, game := range games {
+			game := game
+			pool.submit(func() error { return cli.renderGamePage(game, resources[game.ID]) })
+		}
+	}
+
+	if kinds&matchupPages != 0 {
+		matchups := make(map[dirtyMatchup]bool)
+		for _, game := range games {
+			matchups[newDirtyMatchup(game)] = true
+		}
+		for matchup := range matchups {
+			matchup := matchup
+			pool.submit(func() error { return cli.writeMatchup(matchup) })
+		}
+	}
+
+	if kinds&playerPages != 0 {
+		players, err := cli.store.players()
+		if err != nil {
+			pool.wait()
+			return err
+		}
+		for _, player := range players {
+			player := player
+			pool.submit(func() error { return cli.writePlayerPage(player) })
+		}
+	}
+
+	if kinds&staticAssets != 0 {
+		pool.submit(cli.docGen.staticAssets)
+	}
+
+	var queueErr error
+	if kinds&indexPages != 0 {
+		queueErr = cli.queueIndices(pool)
+	}
+
+	if err = pool.wait(); err != nil {
+		return err
+	}
+	return queueErr
 }
@@ -0,0 +1,1373 @@
+package internal
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// command is a non-interactive action selected by name on the
+// command line
+type command struct {
+	name    string
+	summary string
+	run     func(cli CLI, args []string) error
+}
+
+func commands() []command {
+	return []command{
+		{"migrate", "Upgrade the database schema to the latest version", CLI.migrateCommand},
+		{"add-game", "Add a game and regenerate affected pages", CLI.addGameCommand},
+		{"edit-game", "Edit a game and regenerate affected pages", CLI.editGameCommand},
+		{"delete-game", "Delete a game, its page, and regenerate affected pages", CLI.deleteGameCommand},
+		{"import", "Import games from CSV or JSON files", CLI.importCommand},
+		{"export", "Write every record as a JSON archive", CLI.exportCommand},
+		{"restore", "Load a JSON archive into an empty database", CLI.restoreCommand},
+		{"add-sport", "Add a sport", CLI.addSportCommand},
+		{"edit-sport", "Change how a sport's games divide into periods", CLI.editSportCommand},
+		{"add-league", "Add a league of an existing sport", CLI.addLeagueCommand},
+		{"add-club", "Add a club, optionally active in leagues", CLI.addClubCommand},
+		{"add-club-iteration", "Add a new iteration of a relocated or renamed club", CLI.addClubIterationCommand},
+		{"league-club", "Add or remove a club from a league's active clubs", CLI.leagueClubCommand},
+		{"new-season", "Add a season played by a league's active clubs", CLI.newSeasonCommand},
+		{"add-player", "Add a player, optionally to a club's roster", CLI.addPlayerCommand},
+		{"roster", "List, add or remove players of a club's season roster", CLI.rosterCommand},
+		{"add-stat", "Add a box score column to a sport", CLI.addStatCommand},
+		{"stat-line", "Set or delete a player's stat line in a game", CLI.statLineCommand},
+		{"generate", "Generate indices or the whole site", CLI.generateCommand},
+		{"serve", "Serve the site locally for preview", CLI.serveCommand},
+		{"watch", "Install templates and static assets as they change and rebuild their pages", CLI.watchCommand},
+		{"prune", "Remove pages the database no longer generates", CLI.pruneCommand},
+		{"rollback", "Put the site back to the build before the last full generation", CLI.rollbackCommand},
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: recap [-dry-run] [command] [flags]\n\n")
+	fmt.Fprintf(os.Stderr, "With no command, recap shows an interactive menu.\n")
+	fmt.Fprintf(os.Stderr, "With -dry-run, changes are made to an in-memory copy of the\n")
+	fmt.Fprintf(os.Stderr, "database and the pages that would be written are listed.\n\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	for _, cmd := range commands() {
+		fmt.Fprintf(os.Stderr, "  %-18s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun \"recap [command] -h\" for the flags of a command.\n")
+}
+
+func (cli CLI) run(args []string) {
+	name := args[0]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		usage()
+		return
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			if err := cmd.run(cli, args[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command \"%s\"\n", name)
+	usage()
+	os.Exit(2)
+}
+
+// resourceFlag collects repeated -resource title=url flags
+type resourceFlag []Resource
+
+func (rf *resourceFlag) String() string {
+	pairs := make([]string, len(*rf))
+	for i, r := range *rf {
+		pairs[i] = fmt.Sprintf("%s=%s", r.Title, r.URL)
+	}
+	return strings.Join(pairs, " ")
+}
+
+func (rf *resourceFlag) Set(value string) error {
+	title, url, found := strings.Cut(value, "=")
+	if !found || title == "" || url == "" {
+		return errors.New("resource must be of the form title=url")
+	}
+	*rf = append(*rf, Resource{Title: title, URL: url})
+	return nil
+}
+
+// periodsFlag collects repeated -period home-away flags, one per
+// period of the line score in order
+type periodsFlag []Period
+
+func (pf *periodsFlag) String() string {
+	scores := make([]string, len(*pf))
+	for i, p := range *pf {
+		scores[i] = fmt.Sprintf("%d-%d", p.HomeScore, p.AwayScore)
+	}
+	return strings.Join(scores, " ")
+}
+
+func (pf *periodsFlag) Set(value string) error {
+	var p Period
+	if _, err := fmt.Sscanf(value, "%d-%d", &p.HomeScore, &p.AwayScore); err != nil {
+		return errors.New("period must be of the form home-away, e.g. 21-17")
+	}
+	p.Number = len(*pf) + 1
+	*pf = append(*pf, p)
+	return nil
+}
+
+// statValue is a value given for the stat with Code
+type statValue struct {
+	Code  string
+	Value int
+}
+
+// statValuesFlag collects repeated -stat code=value flags
+type statValuesFlag []statValue
+
+func (sf *statValuesFlag) String() string {
+	pairs := make([]string, len(*sf))
+	for i, sv := range *sf {
+		pairs[i] = fmt.Sprintf("%s=%d", sv.Code, sv.Value)
+	}
+	return strings.Join(pairs, " ")
+}
+
+func (sf *statValuesFlag) Set(value string) error {
+	code, number, found := strings.Cut(value, "=")
+	n, err := strconv.Atoi(number)
+	if !found || code == "" || err != nil {
+		return errors.New("stat must be of the form code=value, e.g. PTS=31")
+	}
+	*sf = append(*sf, statValue{strings.ToUpper(code), n})
+	return nil
+}
+
+// intsFlag collects repeated integer flags
+type intsFlag []int
+
+func (inf *intsFlag) String() string {
+	return fmt.Sprint([]int(*inf))
+}
+
+func (inf *intsFlag) Set(value string) error {
+	var n int
+	if _, err := fmt.Sscan(value, &n); err != nil {
+		return err
+	}
+	*inf = append(*inf, n)
+	return nil
+}
+
+// stringsFlag collects repeated string flags
+type stringsFlag []string
+
+func (sf *stringsFlag) String() string {
+	return strings.Join(*sf, " ")
+}
+
+func (sf *stringsFlag) Set(value string) error {
+	*sf = append(*sf, value)
+	return nil
+}
+
+// flagsSet returns the names of the flags given on the command line
+func flagsSet(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set
+}
+
+func validDate(date string) error {
+	if valid, _ := regexp.MatchString("^[0-9]{4}-[0-9]{1,2}-[0-9]{1,2}$", date); !valid {
+		return fmt.Errorf("invalid date \"%s\", expected YYYY-MM-DD", date)
+	}
+	return nil
+}
+
+func validTime(time string) error {
+	if valid, _ := regexp.MatchString("^([01]?[0-9]|2[0-3]):[0-5][0-9]$", time); !valid {
+		return fmt.Errorf("invalid time \"%s\", expected HH:MM", time)
+	}
+	return nil
+}
+
+func validStatus(status string) error {
+	for _, s := range gameStatuses {
+		if status == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid status \"%s\", expected one of %s",
+		status, strings.Join(gameStatuses, ", "))
+}
+
+func validPeriods(periods int, periodName, overtimeName string) error {
+	if periods < 0 {
+		return errors.New("periods cannot be negative")
+	}
+	if err := validLength("period name", periodName, 1, 32); err != nil {
+		return err
+	}
+	return validLength("overtime name", overtimeName, 0, 32)
+}
+
+func validLength(name, val string, min, max int) error {
+	if len(val) < min || len(val) > max {
+		return fmt.Errorf("%s must be between %d and %d characters", name, min, max)
+	}
+	return nil
+}
+
+// findSeason looks up the season of league with the given start year
+// and type
+func (cli CLI) findSeason(code string, year int, seasonType string) (Season, error) {
+	league, err := cli.store.league(code)
+	if err != nil {
+		return Season{}, fmt.Errorf("league %s: %w", code, err)
+	}
+
+	filter := seasonFilter{}
+	filter.SetLeague(league)
+	seasons, err := cli.store.seasons(filter)
+	if err != nil {
+		return Season{}, err
+	}
+
+	for _, season := range seasons {
+		if season.Year == year && strings.EqualFold(season.Type, seasonType) {
+			return season, nil
+		}
+	}
+	return Season{}, fmt.Errorf("no %d %s season in %s", year, seasonType, league.Code)
+}
+
+// findClub looks up the club with the given ID among the clubs
+// playing in season
+func (cli CLI) findClub(season Season, id int) (Club, error) {
+	clubs, err := cli.store.clubsBySeason(season)
+	if err != nil {
+		return Club{}, err
+	}
+
+	for _, club := range clubs {
+		if club.ID == id {
+			return club, nil
+		}
+	}
+	return Club{}, fmt.Errorf("club %d does not play in the %d %s %s",
+		id, season.Year, season.League.Code, season.Type)
+}
+
+func (cli CLI) addGameCommand(args []string) error {
+	fs := flag.NewFlagSet("add-game", flag.ExitOnError)
+	league := fs.String("league", "", "league code, e.g. NBA")
+	year := fs.Int("season", 0, "start year of the season")
+	seasonType := fs.String("type", "Season", "season type")
+	home := fs.Int("home", 0, "home club ID")
+	away := fs.Int("away", 0, "away club ID")
+	date := fs.String("date", "", "game date as YYYY-MM-DD")
+	clock := fs.String("time", "", "time the game starts as HH:MM")
+	status := fs.String("status", statusFinal, "game status: "+strings.Join(gameStatuses, ", "))
+	homeScore := fs.Int("home-score", 0, "home club score")
+	awayScore := fs.Int("away-score", 0, "away club score")
+	title := fs.String("title", "", "game title")
+	venue := fs.String("venue", "", "game venue")
+	var periods periodsFlag
+	fs.Var(&periods, "period", "score of the next period as home-away (repeatable)")
+	var resources resourceFlag
+	fs.Var(&resources, "resource", "resource as title=url (repeatable)")
+	fs.Parse(args)
+
+	set := flagsSet(fs)
+	for _, required := range []string{"league", "season", "home", "away", "date"} {
+		if !set[required] {
+			return fmt.Errorf("-%s is required", required)
+		}
+	}
+	if *home == *away {
+		return errors.New("home and away clubs must differ")
+	}
+	if err := validDate(*date); err != nil {
+		return err
+	}
+	if set["time"] {
+		if err := validTime(*clock); err != nil {
+			return err
+		}
+	}
+	if err := validStatus(*status); err != nil {
+		return err
+	}
+	if (set["home-score"] || set["away-score"] || len(periods) > 0) && !(Game{Status: *status}).Decided() {
+		return fmt.Errorf("a %s game has no score", *status)
+	}
+	if len(periods) > 0 && !set["home-score"] && !set["away-score"] {
+		*homeScore, *awayScore = periodTotals(periods)
+	}
+	if err := validLength("title", *title, 0, 128); err != nil {
+		return err
+	}
+	if err := validLength("venue", *venue, 0, 128); err != nil {
+		return err
+	}
+	for _, r := range resources {
+		if err := validLength("resource title", r.Title, 1, 128); err != nil {
+			return err
+		}
+		if err := validLength("resource url", r.URL, 1, 256); err != nil {
+			return err
+		}
+	}
+
+	season, err := cli.findSeason(*league, *year, *seasonType)
+	if err != nil {
+		return err
+	}
+	homeClub, err := cli.findClub(season, *home)
+	if err != nil {
+		return err
+	}
+	awayClub, err := cli.findClub(season, *away)
+	if err != nil {
+		return err
+	}
+
+	var game Game
+	err = cli.store.transaction(func(tx store) error {
+		var err error
+		game, err = tx.createGame(season, *date, homeClub, *homeScore,
+			awayClub, *awayScore, *title, *venue, *status, *clock)
+		if err != nil {
+			return err
+		}
+		if len(periods) > 0 {
+			if err := tx.setPeriods(game, periods); err != nil {
+				return err
+			}
+		}
+		for _, r := range resources {
+			if _, err := tx.createResource(game, r.Title, r.URL); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := cli.writeGamePage(game); err != nil {
+		return err
+	}
+
+	fmt.Printf("Added game %d\n", game.ID)
+	dirty := newDirtyPages()
+	dirty.addGame(game)
+	cli.regenerate(dirty)
+	return nil
+}
+
+func (cli CLI) editGameCommand(args []string) error {
+	fs := flag.NewFlagSet("edit-game", flag.ExitOnError)
+	id := fs.Int("id", 0, "ID of the game to edit")
+	date := fs.String("date", "", "game date as YYYY-MM-DD")
+	clock := fs.String("time", "", "time the game starts as HH:MM, empty to clear")
+	status := fs.String("status", "", "game status: "+strings.Join(gameStatuses, ", "))
+	title := fs.String("title", "", "game title")
+	venue := fs.String("venue", "", "game venue")
+	homeScore := fs.Int("home-score", 0, "home club score")
+	awayScore := fs.Int("away-score", 0, "away club score")
+	year := fs.Int("season", 0, "start year of the season to move the game to")
+	seasonType := fs.String("type", "", "type of the season to move the game to (default the game's season type)")
+	home := fs.Int("home", 0, "ID of the club to play at home")
+	away := fs.Int("away", 0, "ID of the club to play away")
+	swap := fs.Bool("swap", false, "swap the home and away clubs and their scores")
+	var periods periodsFlag
+	fs.Var(&periods, "period", "score of the next period as home-away, replacing the line score (repeatable)")
+	clearPeriods := fs.Bool("clear-periods", false, "remove the line score")
+	var addResources resourceFlag
+	fs.Var(&addResources, "add-resource", "resource to add as title=url (repeatable)")
+	var deleteResources intsFlag
+	fs.Var(&deleteResources, "delete-resource", "ID of a resource to delete (repeatable)")
+	fs.Parse(args)
+
+	set := flagsSet(fs)
+	if !set["id"] {
+		return errors.New("-id is required")
+	}
+
+	edit := gameEdit{}
+	if set["date"] {
+		if err := validDate(*date); err != nil {
+			return err
+		}
+		edit.SetDate(*date)
+	}
+	if set["time"] {
+		if *clock != "" {
+			if err := validTime(*clock); err != nil {
+				return err
+			}
+		}
+		edit.SetTime(*clock)
+	}
+	if set["status"] {
+		if err := validStatus(*status); err != nil {
+			return err
+		}
+		edit.SetStatus(*status)
+	}
+	if set["title"] {
+		if err := validLength("title", *title, 0, 128); err != nil {
+			return err
+		}
+		edit.SetTitle(*title)
+	}
+	if set["venue"] {
+		if err := validLength("venue", *venue, 0, 128); err != nil {
+			return err
+		}
+		edit.SetVenue(*venue)
+	}
+	if set["home-score"] {
+		edit.SetHomeScore(*homeScore)
+	}
+	if set["away-score"] {
+		edit.SetAwayScore(*awayScore)
+	}
+	if len(periods) > 0 && *clearPeriods {
+		return errors.New("-period and -clear-periods cannot be combined")
+	}
+	if len(periods) > 0 {
+		edit.SetPeriods(periods)
+		if !set["home-score"] && !set["away-score"] {
+			home, away := periodTotals(periods)
+			edit.SetHomeScore(home)
+			edit.SetAwayScore(away)
+		}
+	}
+	if *clearPeriods {
+		edit.SetPeriods(nil)
+	}
+
+	game, err := cli.store.game(*id)
+	if err != nil {
+		return fmt.Errorf("game %d: %w", *id, err)
+	}
+
+	season := game.Season
+	if set["season"] || set["type"] {
+		if !set["season"] {
+			*year = game.Season.Year
+		}
+		if !set["type"] {
+			*seasonType = game.Season.Type
+		}
+		if season, err = cli.findSeason(game.Season.League.Code, *year, *seasonType); err != nil {
+			return err
+		}
+		edit.SetSeason(season)
+	}
+	if *swap {
+		edit.SwapHomeAway()
+	}
+	if set["home"] {
+		club, err := cli.findClub(season, *home)
+		if err != nil {
+			return err
+		}
+		edit.SetHome(club)
+	}
+	if set["away"] {
+		club, err := cli.findClub(season, *away)
+		if err != nil {
+			return err
+		}
+		edit.SetAway(club)
+	}
+
+	// Resource changes are checked up front and made in the same
+	// transaction as the edit, so a rejected edit changes nothing
+	var removed []Resource
+	if len(deleteResources) > 0 {
+		resources, err := cli.store.resources(game)
+		if err != nil {
+			return err
+		}
+		for _, resourceID := range deleteResources {
+			found := false
+			for _, resource := range resources {
+				if resource.ID == resourceID {
+					found = true
+					removed = append(removed, resource)
+				}
+			}
+			if !found {
+				return fmt.Errorf("game %d has no resource %d", game.ID, resourceID)
+			}
+		}
+	}
+
+	for _, r := range addResources {
+		if err := validLength("resource title", r.Title, 1, 128); err != nil {
+			return err
+		}
+		if err := validLength("resource url", r.URL, 1, 256); err != nil {
+			return err
+		}
+	}
+
+	players, err := cli.gamePlayers(game)
+	if err != nil {
+		return err
+	}
+
+	original := game
+	err = cli.store.transaction(func(tx store) error {
+		for _, resource := range removed {
+			if err := tx.deleteResource(resource); err != nil {
+				return err
+			}
+		}
+		for _, r := range addResources {
+			if _, err := tx.createResource(original, r.Title, r.URL); err != nil {
+				return err
+			}
+		}
+		var err error
+		game, err = tx.editGame(original, edit)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if gamePath(original) != gamePath(game) {
+		if err := cli.docGen.removeGamePage(original); err != nil {
+			return err
+		}
+	}
+
+	if err := cli.writeGamePage(game); err != nil {
+		return err
+	}
+
+	fmt.Printf("Edited game %d\n", game.ID)
+	dirty := newDirtyPages()
+	dirty.addGame(game)
+	dirty.addGame(original)
+	dirty.addPlayers(players)
+	cli.regenerate(dirty)
+	return nil
+}
+
+func (cli CLI) deleteGameCommand(args []string) error {
+	fs := flag.NewFlagSet("delete-game", flag.ExitOnError)
+	id := fs.Int("id", 0, "ID of the game to delete")
+	fs.Parse(args)
+
+	if !flagsSet(fs)["id"] {
+		return errors.New("-id is required")
+	}
+
+	game, err := cli.store.game(*id)
+	if err != nil {
+		return fmt.Errorf("game %d: %w", *id, err)
+	}
+
+	players, err := cli.gamePlayers(game)
+	if err != nil {
+		return err
+	}
+	if err := cli.removeGame(game); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted game %d\n", game.ID)
+	dirty := newDirtyPages()
+	dirty.addGame(game)
+	dirty.addPlayers(players)
+	cli.regenerate(dirty)
+	return nil
+}
+
+func (cli CLI) importCommand(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "", "file format, csv or json (default from file extension)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: recap import [-format csv|json] file...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no files to import")
+	}
+
+	var records []importRecord
+	for _, path := range fs.Args() {
+		fileRecords, err := readImportFile(path, *format)
+		if err != nil {
+			// A file that cannot be read fails as a single row
+			fileRecords = []importRecord{{source: path, err: err}}
+		}
+		records = append(records, fileRecords...)
+	}
+
+	games, err := cli.importGames(records)
+	var errs importErrors
+	if errors.As(err, &errs) {
+		fmt.Fprintln(os.Stderr, errs)
+		rows := "rows"
+		if len(errs) == 1 {
+			rows = "row"
+		}
+		return fmt.Errorf("%d %s failed, nothing imported", len(errs), rows)
+	} else if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d games\n", len(games))
+	return nil
+}
+
+func (cli CLI) exportCommand(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	output := fs.String("o", "", "write the archive to this file instead of stdout")
+	fs.Parse(args)
+
+	a, err := exportArchive(cli.store)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return writeArchive(os.Stdout, a)
+	}
+
+	file, err := createFile(*output)
+	if err != nil {
+		return err
+	}
+	if err = writeArchive(file, a); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (cli CLI) restoreCommand(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	generate := fs.Bool("generate", false, "generate the site once the archive is loaded")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: recap restore [-generate] archive.json\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected exactly one archive")
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	a, err := readArchive(file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+
+	if err = restoreArchive(cli.store, a); err != nil {
+		return err
+	}
+	fmt.Printf("Restored %d leagues, %d seasons, %d clubs and %d games\n",
+		len(a.Leagues), len(a.Seasons), len(a.Clubs), len(a.Games))
+
+	if *generate {
+		cli.generateSite()
+	}
+	return nil
+}
+
+func (cli CLI) migrateCommand(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	status := fs.Bool("status", false, "only report the schema version")
+	fs.Parse(args)
+
+	db, managed := cli.store.(migrator)
+	if !managed {
+		return errors.New("this database does not use migrations")
+	}
+
+	version, err := db.schemaVersion()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Schema version %d, latest %d\n", version, latestVersion(cli.migrations))
+	if *status {
+		return nil
+	}
+
+	applied, err := migrate(db, cli.migrations)
+	for _, m := range applied {
+		fmt.Printf("Applied %s\n", m.name)
+	}
+	return err
+}
+
+func (cli CLI) generateCommand(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	jobs := fs.Int("jobs", runtime.NumCPU(), "number of pages to render at once")
+	prune := fs.Bool("prune", false, "remove stale pages after generating the site")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: recap generate [-jobs n] [-prune] indices|site\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *jobs < 1 {
+		return errors.New("-jobs must be at least 1")
+	}
+	cli.jobs = *jobs
+	if *prune && fs.Arg(0) != "site" {
+		return errors.New("-prune only applies to generating the site")
+	}
+	cli.pruneSite = *prune
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected exactly one target")
+	}
+
+	switch fs.Arg(0) {
+	case "indices":
+		cli.generateIndices()
+	case "site":
+		cli.generateSite()
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown target \"%s\"", fs.Arg(0))
+	}
+	return nil
+}
+
+func (cli CLI) addSportCommand(args []string) error {
+	fs := flag.NewFlagSet("add-sport", flag.ExitOnError)
+	name := fs.String("name", "", "name of the sport, e.g. Basketball")
+	periods := fs.Int("periods", 0, "number of periods in regulation, 0 for no line scores")
+	periodName := fs.String("period-name", "Period", "name of a period, e.g. Quarter")
+	overtimeName := fs.String("overtime-name", "OT", "name of a period past regulation, empty to number them on")
+	fs.Parse(args)
+
+	if err := validLength("name", *name, 1, 64); err != nil {
+		return err
+	}
+	if err := validPeriods(*periods, *periodName, *overtimeName); err != nil {
+		return err
+	}
+
+	sport, err := cli.store.createSport(*name, *periods, *periodName, *overtimeName)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added sport %d: %s\n", sport.ID, sport.Name)
+	return nil
+}
+
+func (cli CLI) editSportCommand(args []string) error {
+	fs := flag.NewFlagSet("edit-sport", flag.ExitOnError)
+	name := fs.String("name", "", "name of the sport to edit")
+	periods := fs.Int("periods", 0, "number of periods in regulation, 0 for no line scores")
+	periodName := fs.String("period-name", "", "name of a period, e.g. Quarter")
+	overtimeName := fs.String("overtime-name", "", "name of a period past regulation, empty to number them on")
+	fs.Parse(args)
+
+	set := flagsSet(fs)
+	if !set["name"] {
+		return errors.New("-name is required")
+	}
+
+	sport, err := cli.findSport(*name)
+	if err != nil {
+		return err
+	}
+	if set["periods"] {
+		sport.Periods = *periods
+	}
+	if set["period-name"] {
+		sport.PeriodName = *periodName
+	}
+	if set["overtime-name"] {
+		sport.OvertimeName = *overtimeName
+	}
+	if err := validPeriods(sport.Periods, sport.PeriodName, sport.OvertimeName); err != nil {
+		return err
+	}
+
+	if sport, err = cli.store.editSport(sport); err != nil {
+		return err
+	}
+	fmt.Printf("Edited sport %d: %s\n", sport.ID, sport.Name)
+	return nil
+}
+
+func (cli CLI) addLeagueCommand(args []string) error {
+	fs := flag.NewFlagSet("add-league", flag.ExitOnError)
+	code := fs.String("code", "", "league code, e.g. NBA")
+	name := fs.String("name", "", "full name of the league")
+	sportName := fs.String("sport", "", "name of the sport the league plays")
+	fs.Parse(args)
+
+	if err := validLeagueCode(*code); err != nil {
+		return err
+	}
+	if err := validLength("name", *name, 1, 128); err != nil {
+		return err
+	}
+	sport, err := cli.findSport(*sportName)
+	if err != nil {
+		return err
+	}
+
+	league, err := cli.store.createLeague(*code, *name, sport)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added league %s: %s %s\n", league.Code, league.Name, league.Sport)
+	return nil
+}
+
+func (cli CLI) addClubCommand(args []string) error {
+	fs := flag.NewFlagSet("add-club", flag.ExitOnError)
+	represents := fs.String("represents", "", "place or institution the club represents")
+	nickname := fs.String("nickname", "", "nickname of the club")
+	var codes stringsFlag
+	fs.Var(&codes, "league", "code of a league to make the club active in (repeatable)")
+	fs.Parse(args)
+
+	if err := validClub(*represents, *nickname); err != nil {
+		return err
+	}
+	leagues := make([]League, len(codes))
+	for i, code := range codes {
+		league, err := cli.store.league(strings.ToUpper(code))
+		if err != nil {
+			return fmt.Errorf("league %s: %w", code, err)
+		}
+		leagues[i] = league
+	}
+
+	var club Club
+	err := cli.store.transaction(func(tx store) error {
+		var err error
+		if club, err = tx.createClub(*represents, *nickname); err != nil {
+			return err
+		}
+		for _, league := range leagues {
+			if err = tx.setActiveClub(league, club); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Added club %d: %s\n", club.ID, clubName(club))
+	for _, league := range leagues {
+		fmt.Printf("Active in %s\n", league.Code)
+	}
+	return nil
+}
+
+func (cli CLI) addClubIterationCommand(args []string) error {
+	fs := flag.NewFlagSet("add-club-iteration", flag.ExitOnError)
+	id := fs.Int("club", 0, "ID of the club")
+	represents := fs.String("represents", "", "place or institution the club now represents")
+	nickname := fs.String("nickname", "", "new nickname of the club")
+	activate := fs.Bool("activate", true, "make the new iteration active in every league the club is active in")
+	fs.Parse(args)
+
+	if !flagsSet(fs)["club"] {
+		return errors.New("-club is required")
+	}
+	if err := validClub(*represents, *nickname); err != nil {
+		return err
+	}
+	club, err := cli.findClubIteration(*id, 0)
+	if err != nil {
+		return err
+	}
+
+	var leagues []League
+	if *activate {
+		if leagues, err = cli.activeLeagues(club); err != nil {
+			return err
+		}
+	}
+
+	next, err := cli.addClubIteration(club, *represents, *nickname, leagues)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Added iteration %d of club %d: %s\n", next.Iteration, next.ID, clubName(next))
+	for _, league := range leagues {
+		fmt.Printf("Active in %s\n", league.Code)
+	}
+	return nil
+}
+
+func (cli CLI) leagueClubCommand(args []string) error {
+	fs := flag.NewFlagSet("league-club", flag.ExitOnError)
+	code := fs.String("league", "", "league code, e.g. NBA")
+	id := fs.Int("club", 0, "ID of the club")
+	iteration := fs.Int("iteration", 0, "iteration of the club to make active (default latest)")
+	remove := fs.Bool("remove", false, "remove the club from the league's active clubs")
+	fs.Parse(args)
+
+	set := flagsSet(fs)
+	if !set["league"] || !set["club"] {
+		return errors.New("-league and -club are required")
+	}
+	league, err := cli.store.league(strings.ToUpper(*code))
+	if err != nil {
+		return fmt.Errorf("league %s: %w", *code, err)
+	}
+	club, err := cli.findClubIteration(*id, *iteration)
+	if err != nil {
+		return err
+	}
+
+	if *remove {
+		if err := cli.store.removeActiveClub(league, club); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s from %s\n", clubName(club), league.Code)
+		return nil
+	}
+
+	if err := cli.store.setActiveClub(league, club); err != nil {
+		return err
+	}
+	fmt.Printf("%s (iteration %d) is active in %s\n", clubName(club), club.Iteration, league.Code)
+	return nil
+}
+
+func (cli CLI) newSeasonCommand(args []string) error {
+	fs := flag.NewFlagSet("new-season", flag.ExitOnError)
+	active := fs.Bool("a", false, "make the season the league's active season")
+	exhibition := fs.Bool("e", false, "the season is an exhibition")
+	description := fs.String("d", "Season", "description of the season, e.g. Playoffs")
+	indices := fs.Bool("indices", false, "regenerate indices once the season is active")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: recap new-season [-a] [-e] [-d description] [-indices] league start_year\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("expected a league and a start year")
+	}
+	year, err := strconv.Atoi(fs.Arg(1))
+	if err != nil || year < 1 {
+		return fmt.Errorf("invalid start year \"%s\"", fs.Arg(1))
+	}
+	if err := validLength("description", *description, 1, 32); err != nil {
+		return err
+	}
+	if *indices && !*active {
+		return errors.New("-indices requires -a")
+	}
+
+	league, err := cli.store.league(strings.ToUpper(fs.Arg(0)))
+	if err != nil {
+		return fmt.Errorf("league %s: %w", fs.Arg(0), err)
+	}
+
+	season, err := cli.store.createSeason(league, year, *description, *exhibition, *active)
+	if err != nil {
+		return err
+	}
+	clubs, err := cli.store.clubsBySeason(season)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Added season %d: %d %s %s\n", season.ID, season.Year, league.Code, season.Type)
+	if *active {
+		fmt.Printf("Active season of %s\n", league.Code)
+	}
+	fmt.Printf("Copied %d active clubs:\n", len(clubs))
+	for _, club := range clubs {
+		fmt.Printf("  %d %s\n", club.ID, clubName(club))
+	}
+
+	if *indices {
+		cli.generateIndices()
+	}
+	return nil
+}
+
+// rosterFlags adds the flags identifying a club's season roster to fs
+func rosterFlags(fs *flag.FlagSet) (*string, *int, *string, *int) {
+	league := fs.String("league", "", "league code, e.g. NBA")
+	year := fs.Int("season", 0, "start year of the season")
+	seasonType := fs.String("type", "Season", "season type")
+	club := fs.Int("club", 0, "ID of the club")
+	return league, year, seasonType, club
+}
+
+func (cli CLI) addPlayerCommand(args []string) error {
+	fs := flag.NewFlagSet("add-player", flag.ExitOnError)
+	name := fs.String("name", "", "name of the player")
+	league, year, seasonType, id := rosterFlags(fs)
+	fs.Parse(args)
+
+	if err := validLength("name", *name, 1, 128); err != nil {
+		return err
+	}
+
+	set := flagsSet(fs)
+	rostered := set["league"] || set["season"] || set["club"]
+	var season Season
+	var club Club
+	if rostered {
+		if !set["league"] || !set["season"] || !set["club"] {
+			return errors.New("-league, -season and -club are required to add the player to a roster")
+		}
+		var err error
+		if season, err = cli.findSeason(*league, *year, *seasonType); err != nil {
+			return err
+		}
+		if club, err = cli.findClub(season, *id); err != nil {
+			return err
+		}
+	}
+
+	var player Player
+	err := cli.store.transaction(func(tx store) error {
+		var err error
+		if player, err = tx.createPlayer(*name); err != nil {
+			return err
+		}
+		if rostered {
+			return tx.addRosterPlayer(season, club, player)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Added player %d: %s\n", player.ID, player.Name)
+	if rostered {
+		fmt.Printf("On the %d %s %s roster of %s\n",
+			season.Year, season.League.Code, season.Type, clubName(club))
+	}
+	return cli.writePlayerPage(player)
+}
+
+func (cli CLI) rosterCommand(args []string) error {
+	fs := flag.NewFlagSet("roster", flag.ExitOnError)
+	league, year, seasonType, id := rosterFlags(fs)
+	var add intsFlag
+	fs.Var(&add, "add", "ID of a player to add (repeatable)")
+	var remove intsFlag
+	fs.Var(&remove, "remove", "ID of a player to remove (repeatable)")
+	fs.Parse(args)
+
+	set := flagsSet(fs)
+	for _, required := range []string{"league", "season", "club"} {
+		if !set[required] {
+			return fmt.Errorf("-%s is required", required)
+		}
+	}
+
+	season, err := cli.findSeason(*league, *year, *seasonType)
+	if err != nil {
+		return err
+	}
+	club, err := cli.findClub(season, *id)
+	if err != nil {
+		return err
+	}
+
+	err = cli.store.transaction(func(tx store) error {
+		for _, playerID := range add {
+			player, err := tx.player(playerID)
+			if err != nil {
+				return fmt.Errorf("player %d: %w", playerID, err)
+			}
+			if err := tx.addRosterPlayer(season, club, player); err != nil {
+				return err
+			}
+		}
+		for _, playerID := range remove {
+			if err := tx.removeRosterPlayer(season, club, Player{ID: playerID}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	roster, err := cli.store.roster(season, club)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d %s %s roster of %s:\n", season.Year, season.League.Code, season.Type, clubName(club))
+	for _, player := range roster {
+		fmt.Printf("  %d %s\n", player.ID, player.Name)
+	}
+	return nil
+}
+
+func (cli CLI) addStatCommand(args []string) error {
+	fs := flag.NewFlagSet("add-stat", flag.ExitOnError)
+	sportName := fs.String("sport", "", "name of the sport")
+	code := fs.String("code", "", "column heading of the stat, e.g. PTS")
+	name := fs.String("name", "", "full name of the stat, e.g. Points")
+	fs.Parse(args)
+
+	if err := validLength("code", *code, 1, 8); err != nil {
+		return err
+	}
+	if err := validLength("name", *name, 1, 64); err != nil {
+		return err
+	}
+	sport, err := cli.findSport(*sportName)
+	if err != nil {
+		return err
+	}
+
+	stat, err := cli.store.createStat(sport, *code, *name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added %s stat %d: %s (%s)\n", sport.Name, stat.ID, stat.Code, stat.Name)
+	return nil
+}
+
+func (cli CLI) statLineCommand(args []string) error {
+	fs := flag.NewFlagSet("stat-line", flag.ExitOnError)
+	gameID := fs.Int("game", 0, "ID of the game")
+	playerID := fs.Int("player", 0, "ID of the player")
+	clubID := fs.Int("club", 0, "ID of the club the player played for (default the club whose roster they are on)")
+	var values statValuesFlag
+	fs.Var(&values, "stat", "stat as code=value, e.g. PTS=31 (repeatable)")
+	del := fs.Bool("delete", false, "delete the player's stat line")
+	fs.Parse(args)
+
+	set := flagsSet(fs)
+	if !set["game"] || !set["player"] {
+		return errors.New("-game and -player are required")
+	}
+	if *del && len(values) > 0 {
+		return errors.New("-stat and -delete cannot be combined")
+	}
+
+	game, err := cli.store.game(*gameID)
+	if err != nil {
+		return fmt.Errorf("game %d: %w", *gameID, err)
+	}
+	player, err := cli.store.player(*playerID)
+	if err != nil {
+		return fmt.Errorf("player %d: %w", *playerID, err)
+	}
+
+	if *del {
+		if err := cli.store.deleteStatLine(game, player); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted the stat line of %s in game %d\n", player.Name, game.ID)
+	} else {
+		line := StatLine{GameID: game.ID, Player: player, ClubID: *clubID, Values: make(map[int]int)}
+		if !set["club"] {
+			if line.ClubID, err = cli.rosterClub(game, player); err != nil {
+				return err
+			}
+		}
+
+		sport, err := cli.findSport(game.Season.League.Sport)
+		if err != nil {
+			return err
+		}
+		stats, err := cli.store.stats(sport)
+		if err != nil {
+			return err
+		}
+		for _, sv := range values {
+			found := false
+			for _, stat := range stats {
+				if stat.Code == sv.Code {
+					line.Values[stat.ID] = sv.Value
+					found = true
+				}
+			}
+			if !found {
+				return fmt.Errorf("no %s stat %s", sport.Name, sv.Code)
+			}
+		}
+
+		if err := cli.store.setStatLine(game, line); err != nil {
+			return err
+		}
+		fmt.Printf("Set the stat line of %s in game %d\n", player.Name, game.ID)
+	}
+
+	if err := cli.writeGamePage(game); err != nil {
+		return err
+	}
+	return cli.writePlayerPage(player)
+}
+
+// rosterClub finds which of the clubs of game has player on its
+// roster for the game's season
+func (cli CLI) rosterClub(game Game, player Player) (int, error) {
+	var clubs []int
+	for _, club := range []Club{game.Home, game.Away} {
+		roster, err := cli.store.roster(game.Season, club)
+		if err != nil {
+			return 0, err
+		}
+		for _, p := range roster {
+			if p.ID == player.ID {
+				clubs = append(clubs, club.ID)
+			}
+		}
+	}
+
+	switch len(clubs) {
+	case 1:
+		return clubs[0], nil
+	case 0:
+		return 0, fmt.Errorf("%s is on neither roster of game %d", player.Name, game.ID)
+	default:
+		return 0, fmt.Errorf("%s is on both rosters of game %d, give -club", player.Name, game.ID)
+	}
+}
+
+func (cli CLI) rollbackCommand(args []string) error {
+	fs := flag.NewFlagSet("rollback", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: recap rollback\n\nRunning rollback again undoes it.\n")
+	}
+	fs.Parse(args)
+
+	if cli.docGen.dryRun {
+		target, err := os.Readlink(cli.docGen.builds.previous)
+		if err != nil {
+			return errors.New("no previous build to roll back to")
+		}
+		fmt.Printf("Would roll back to build %s\n", filepath.Base(target))
+		return nil
+	}
+
+	build, err := cli.docGen.builds.rollback()
+	if err != nil {
+		return err
+	}
+
+	// The manifest describes the build rolled back from, so it is reset
+	// to the pages of the build now live, which the next run stages
+	live, err := filepath.EvalSymlinks(cli.docGen.builds.www)
+	if err != nil {
+		return err
+	}
+	pages, err := sitePages(live)
+	if err != nil {
+		return err
+	}
+	if err := cli.docGen.manifest.reset(pages); err != nil {
+		return err
+	}
+	fmt.Printf("Rolled back to build %s\n", build)
+	return nil
+}
+
+func (cli CLI) pruneCommand(args []string) error {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "list the stale files without removing them")
+	fs.Parse(args)
+
+	list := *dryRun || cli.docGen.dryRun
+	n, err := cli.prune(cli.docGen.staticPath, list)
+	if err != nil {
+		return err
+	}
+	if list {
+		fmt.Printf("Would remove %d stale files\n", n)
+	} else {
+		fmt.Printf("Removed %d stale files\n", n)
+	}
+	return nil
+}
+
+func (cli CLI) serveCommand(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	regenerate := fs.Bool("regenerate", false, "bring pages up to date with the database and templates when requested")
+	jobs := fs.Int("jobs", runtime.NumCPU(), "number of pages to render at once when regenerating")
+	fs.Parse(args)
+
+	if *regenerate && cli.docGen.dryRun {
+		return errors.New("-regenerate cannot be used in a dry run")
+	}
+	if *jobs < 1 {
+		return errors.New("-jobs must be at least 1")
+	}
+	cli.jobs = *jobs
+
+	fmt.Printf("Serving %s at http://%s/\n", cli.docGen.staticPath, *addr)
+	return http.ListenAndServe(*addr, newSiteServer(cli, *regenerate))
+}
+
+func (cli CLI) watchCommand(args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	src := fs.String("src", "web", "directory holding the templates and static directories to watch")
+	interval := fs.Duration("interval", 500*time.Millisecond, "how often to check for changes")
+	jobs := fs.Int("jobs", runtime.NumCPU(), "number of pages to render at once")
+	fs.Parse(args)
+
+	if cli.docGen.dryRun {
+		return errors.New("watch cannot be used in a dry run")
+	}
+	if *interval <= 0 {
+		return errors.New("-interval must be positive")
+	}
+	if *jobs < 1 {
+		return errors.New("-jobs must be at least 1")
+	}
+	for _, dir := range []string{"templates", "static"} {
+		if _, err := os.Stat(filepath.Join(*src, dir)); err != nil {
+			return err
+		}
+	}
+	cli.jobs = *jobs
+
+	fmt.Printf("Watching %s\n", *src)
+	newWatcher(cli, *src).watch(*interval)
+	return nil
+}
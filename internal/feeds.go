@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Feeds are Atom documents written next to the index page they
+// follow. Links are relative to the feed, like those of the pages.
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr,omitempty"`
+	HREF string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Link    atomLink `xml:"link"`
+	Summary string   `xml:"summary"`
+}
+
+// feedPath is the path of the feed following the page at pagePath
+func feedPath(pagePath string) string {
+	return fmt.Sprintf("%s.atom", strings.TrimSuffix(pagePath, ".html"))
+}
+
+// feedID is a stable identifier for the document at path
+func feedID(path string) string {
+	return fmt.Sprintf("urn:recap:%s", path)
+}
+
+// feedTime converts a game date to an Atom timestamp
+func feedTime(date string) string {
+	return fmt.Sprintf("%sT00:00:00Z", date)
+}
+
+func gameSummary(game Game) string {
+	summary := fmt.Sprintf("%s: %s %s vs. %s %s", game.StatusLine(),
+		game.Home.Represents, game.Home.Nickname,
+		game.Away.Represents, game.Away.Nickname)
+	if game.Decided() {
+		summary = fmt.Sprintf("%s: %s %s %d, %s %s %d", game.StatusLine(),
+			game.Home.Represents, game.Home.Nickname, game.HomeScore,
+			game.Away.Represents, game.Away.Nickname, game.AwayScore)
+	}
+	if game.Venue != "" {
+		summary = fmt.Sprintf("%s at %s", summary, game.Venue)
+	}
+	return summary
+}
+
+// newFeed creates the feed of games listed on the page at pagePath,
+// which are ordered newest first. pathToRoot leads from the directory
+// of the page back to the root of the site.
+func newFeed(title, pagePath, pathToRoot string, games []Game) atomFeed {
+	feed := atomFeed{
+		ID:    feedID(feedPath(pagePath)),
+		Title: fmt.Sprintf("Recap: %s", title),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", HREF: path.Base(feedPath(pagePath))},
+			{Rel: "alternate", Type: "text/html", HREF: path.Base(pagePath)},
+		},
+		Author:  atomAuthor{"Recap"},
+		Updated: feedTime("1970-01-01"),
+		Entries: make([]atomEntry, len(games)),
+	}
+	if len(games) > 0 {
+		feed.Updated = feedTime(games[0].Date)
+	}
+
+	for i, game := range games {
+		title := fmt.Sprintf("%s vs. %s", game.Home.Nickname, game.Away.Nickname)
+		if game.Title != "" {
+			title = fmt.Sprintf("%s: %s", title, game.Title)
+		}
+		feed.Entries[i] = atomEntry{
+			ID:      feedID(gamePath(game)),
+			Title:   title,
+			Updated: feedTime(game.Date),
+			Link:    atomLink{Rel: "alternate", Type: "text/html", HREF: pathToRoot + gamePath(game)},
+			Summary: gameSummary(game),
+		}
+	}
+
+	return feed
+}
+
+func writeFeed(w io.Writer, feed atomFeed) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(feed); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -2,8 +2,8 @@ package internal
 
 import (
 	"fmt"
-	"html/template"
 	"io"
+	"path"
 	"sort"
 	"strings"
 	"time"
@@ -30,7 +30,10 @@ type indexPage struct {
 	Breadcrumb breadcrumb
 	Title      string
 	Subtitle   string
+	Feed       string
+	Calendar   string
 	Games      []Game
+	Sidebar    []navSection
 }
 
 type navSection struct {
@@ -38,11 +41,6 @@ type navSection struct {
 	Links  []link
 }
 
-type sidebar struct {
-	Breadcrumb breadcrumb
-	Sections   []navSection
-}
-
 func dateShort(date string) string {
 	t, _ := time.Parse("2006-01-02", date)
 	return t.Format("Jan 02 2006")
@@ -69,6 +67,20 @@ func clubPath(club Club, season Season) string {
 		club.ID)
 }
 
+func standingsPath(season Season) string {
+	return fmt.Sprintf("/%s/%d/%s/standings.html",
+		season.League.Code,
+		season.Year,
+		season.Type)
+}
+
+func matchupPath(league League, a, b Club) string {
+	if b.ID < a.ID {
+		a, b = b, a
+	}
+	return fmt.Sprintf("/%s/matchups/%d-%d.html", league.Code, a.ID, b.ID)
+}
+
 func leaguePath(league League) string {
 	return fmt.Sprintf("/%s/index.html", league.Code)
 }
@@ -77,7 +89,7 @@ func indexPath() string {
 	return "/index.html"
 }
 
-func (doc document) game(game Game, resources []Resource) error {
+func (doc document) game(game Game, resources []Resource, line lineScore, scores []boxScore) error {
 
 	crumbs := breadcrumb{}
 	crumbs.PathToRoot = "../../../.."
@@ -85,16 +97,24 @@ func (doc document) game(game Game, resources []Resource) error {
 	crumbs.Home = link{clubPath(game.Home, game.Season), fmt.Sprintf("%s %s", game.Home.Represents, game.Home.Nickname)}
 	crumbs.Away = link{clubPath(game.Away, game.Season), fmt.Sprintf("%s %s", game.Away.Represents, game.Away.Nickname)}
 
+	matchup := link{
+		matchupPath(game.Season.League, game.Home, game.Away),
+		fmt.Sprintf("%s vs. %s all-time", game.Home.Nickname, game.Away.Nickname),
+	}
+
 	data := struct {
 		Breadcrumb breadcrumb
 		Game       Game
+		Matchup    link
+		LineScore  lineScore
+		BoxScores  []boxScore
 		Resources  []Resource
-	}{crumbs, game, resources}
+	}{crumbs, game, matchup, line, scores, resources}
 
 	return doc.gameTemplate.Execute(doc, data)
 }
 
-func (doc document) club(club Club, season Season, games []Game) error {
+func (doc document) club(club Club, season Season, games []Game, sidebar []navSection) error {
 
 	crumbs := breadcrumb{}
 	crumbs.PathToRoot = "../../../.."
@@ -104,16 +124,15 @@ func (doc document) club(club Club, season Season, games []Game) error {
 	page.Breadcrumb = crumbs
 	page.Title = fmt.Sprintf("%s %s", club.Represents, club.Nickname)
 	page.Subtitle = fmt.Sprintf("%d %s %s", season.Year, season.League.Name, season.Type)
+	page.Feed = path.Base(feedPath(clubPath(club, season)))
+	page.Calendar = path.Base(calendarPath(clubPath(club, season)))
 	page.Games = games
+	page.Sidebar = sidebar
 
-	index := template.Must(
-		template.Must(doc.indexTemplate.Clone()).
-			ParseFiles(doc.clubSidebarPath(club, season.League)))
-
-	return index.Execute(doc, page)
+	return doc.indexTemplate.Execute(doc, page)
 }
 
-func (doc document) league(league League, games []Game) error {
+func (doc document) league(league League, games []Game, sidebar []navSection) error {
 
 	crumbs := breadcrumb{}
 	crumbs.PathToRoot = ".."
@@ -121,16 +140,14 @@ func (doc document) league(league League, games []Game) error {
 	var page indexPage
 	page.Breadcrumb = crumbs
 	page.Title = fmt.Sprintf("%s %s", league.Name, league.Sport)
+	page.Feed = path.Base(feedPath(leaguePath(league)))
 	page.Games = games
+	page.Sidebar = sidebar
 
-	index := template.Must(
-		template.Must(doc.indexTemplate.Clone()).
-			ParseFiles(doc.leagueSidebarPath(league)))
-
-	return index.Execute(doc, page)
+	return doc.indexTemplate.Execute(doc, page)
 }
 
-func (doc document) index(games []Game) error {
+func (doc document) index(games []Game, sidebar []navSection) error {
 
 	crumbs := breadcrumb{}
 	crumbs.PathToRoot = "."
@@ -138,19 +155,127 @@ func (doc document) index(games []Game) error {
 	var page indexPage
 	page.Breadcrumb = crumbs
 	page.Title = "Recent Games"
+	page.Feed = path.Base(feedPath(indexPath()))
 	page.Games = games
+	page.Sidebar = sidebar
+
+	return doc.indexTemplate.Execute(doc, page)
+}
 
-	index := template.Must(
-		template.Must(doc.indexTemplate.Clone()).
-			ParseFiles(doc.indexSidebarPath()))
+func (doc document) standings(season Season, lines []standing, seasons []Season) error {
 
-	return index.Execute(doc, page)
+	crumbs := breadcrumb{}
+	crumbs.PathToRoot = "../../.."
+	crumbs.League = link{leaguePath(season.League), season.League.Name}
+
+	var teams navSection
+	teams.Header = "Teams"
+	teams.Links = make([]link, len(lines))
+	for i, line := range lines {
+		teams.Links[i] = link{
+			clubPath(line.Club, season),
+			fmt.Sprintf("%s %s", line.Club.Represents, line.Club.Nickname),
+		}
+	}
+	sort.Slice(teams.Links, func(i, j int) bool {
+		return strings.ToLower(teams.Links[i].Display) < strings.ToLower(teams.Links[j].Display)
+	})
+
+	data := struct {
+		Breadcrumb breadcrumb
+		Title      string
+		Season     Season
+		Calendar   string
+		Standings  []standing
+		Sections   []navSection
+	}{
+		crumbs,
+		fmt.Sprintf("%d %s %s Standings", season.Year, season.League.Name, season.Type),
+		season,
+		path.Base(seasonCalendarPath(season)),
+		lines,
+		[]navSection{teams, standingsSection(seasons)},
+	}
+
+	return doc.standingsTemplate.Execute(doc, data)
 }
 
-func (doc document) clubSidebar(club Club, seasons []Season) error {
+func (doc document) matchup(league League, m matchup) error {
 
 	crumbs := breadcrumb{}
-	crumbs.PathToRoot = "../../../.."
+	crumbs.PathToRoot = "../.."
+	crumbs.League = link{leaguePath(league), league.Name}
+
+	// Link to each club's page for the season they last met in
+	latest := m.Games[0].Season
+	teams := navSection{Header: "Teams"}
+	teams.Links = []link{
+		{clubPath(m.A, latest), fmt.Sprintf("%d %s %s", latest.Year, m.A.Represents, m.A.Nickname)},
+		{clubPath(m.B, latest), fmt.Sprintf("%d %s %s", latest.Year, m.B.Represents, m.B.Nickname)},
+	}
+
+	data := struct {
+		Breadcrumb breadcrumb
+		Title      string
+		Matchup    matchup
+		Sections   []navSection
+	}{
+		crumbs,
+		fmt.Sprintf("%s %s vs. %s %s", m.A.Represents, m.A.Nickname, m.B.Represents, m.B.Nickname),
+		m,
+		[]navSection{teams},
+	}
+
+	return doc.matchupTemplate.Execute(doc, data)
+}
+
+func (doc document) player(player Player, seasons []playerSeason) error {
+
+	crumbs := breadcrumb{}
+	crumbs.PathToRoot = ".."
+
+	// Link to the page of each club the player appeared for, by season
+	teams := navSection{Header: "Teams"}
+	for _, ps := range seasons {
+		club := ps.Games[0].Club
+		teams.Links = append(teams.Links, link{
+			clubPath(club, ps.Season),
+			fmt.Sprintf("%d %s %s %s", ps.Season.Year, ps.Season.League.Code, club.Represents, club.Nickname),
+		})
+	}
+
+	data := struct {
+		Breadcrumb breadcrumb
+		Title      string
+		Seasons    []playerSeason
+		Sections   []navSection
+	}{crumbs, player.Name, seasons, []navSection{teams}}
+
+	return doc.playerTemplate.Execute(doc, data)
+}
+
+// standingsSection links to the standings of each season, newest first
+func standingsSection(seasons []Season) navSection {
+	sorted := make([]Season, len(seasons))
+	copy(sorted, seasons)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[j].Year < sorted[i].Year
+	})
+
+	section := navSection{Header: "Standings"}
+	section.Links = make([]link, len(sorted))
+	for i, season := range sorted {
+		section.Links[i] = link{
+			standingsPath(season),
+			fmt.Sprintf("%d %s", season.Year, season.Type),
+		}
+	}
+	return section
+}
+
+// clubSidebar links to the other seasons of a club, given every season
+// it played in the league
+func clubSidebar(club Club, seasons []Season) []navSection {
 
 	// Separate out normal seasons from exhibition seasons
 	regular := make([]Season, 0, len(seasons))
@@ -200,13 +325,12 @@ func (doc document) clubSidebar(club Club, seasons []Season) error {
 		data = append(data, section)
 	}
 
-	return doc.sidebarTemplate.Execute(doc, sidebar{Breadcrumb: crumbs, Sections: data})
+	return data
 }
 
-func (doc document) leagueSidebar(season Season, clubs []Club) error {
-
-	crumbs := breadcrumb{}
-	crumbs.PathToRoot = ".."
+// leagueSidebar links to the active clubs of a league in its active
+// season, and to the standings of its seasons
+func leagueSidebar(season Season, clubs []Club, seasons []Season) []navSection {
 
 	var section navSection
 	section.Header = "Teams"
@@ -223,13 +347,16 @@ func (doc document) leagueSidebar(season Season, clubs []Club) error {
 		}
 	}
 
-	return doc.sidebarTemplate.Execute(doc, sidebar{Breadcrumb: crumbs, Sections: []navSection{section}})
-}
+	sections := []navSection{section}
+	if len(seasons) != 0 {
+		sections = append(sections, standingsSection(seasons))
+	}
 
-func (doc document) indexSidebar(leagues []League) error {
+	return sections
+}
 
-	crumbs := breadcrumb{}
-	crumbs.PathToRoot = "."
+// indexSidebar links to every league
+func indexSidebar(leagues []League) []navSection {
 
 	var section navSection
 	section.Header = "Leagues"
@@ -246,5 +373,5 @@ func (doc document) indexSidebar(leagues []League) error {
 		}
 	}
 
-	return doc.sidebarTemplate.Execute(doc, sidebar{Breadcrumb: crumbs, Sections: []navSection{section}})
+	return []navSection{section}
 }
@@ -0,0 +1,395 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// importRecord is a single game read from an import file, identifying
+// its league, season and clubs by code, year/type and name
+type importRecord struct {
+	League    string           `json:"league"`
+	Season    int              `json:"season"`
+	Type      string           `json:"type"`
+	Date      string           `json:"date"`
+	Home      string           `json:"home"`
+	Away      string           `json:"away"`
+	HomeScore int              `json:"home_score"`
+	AwayScore int              `json:"away_score"`
+	Title     string           `json:"title"`
+	Venue     string           `json:"venue"`
+	Status    string           `json:"status"`
+	Time      string           `json:"time"`
+	Resources []importResource `json:"resources"`
+
+	// source locates the record in its file for error reporting, and
+	// err is why it could not be read, if it could not
+	source string
+	err    error
+}
+
+type importResource struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// importRow is an importRecord with its league, season and clubs
+// resolved against the store
+type importRow struct {
+	record importRecord
+	season Season
+	home   Club
+	away   Club
+}
+
+// importErrors collects the failures of every row in an import
+type importErrors []error
+
+func (ie importErrors) Error() string {
+	msgs := make([]string, len(ie))
+	for i, err := range ie {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// readImportFile reads the games in path, in the given format or,
+// if format is empty, the format named by the file extension
+func readImportFile(path, format string) ([]importRecord, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch format {
+	case "csv":
+		return readImportCSV(file, path)
+	case "json":
+		return readImportJSON(file, path)
+	default:
+		return nil, fmt.Errorf("%s: unknown import format \"%s\"", path, format)
+	}
+}
+
+// readImportCSV reads games from CSV with a header row naming the
+// columns league, season, type, date, time, status, home, away,
+// home_score, away_score, title and venue. Scores may be left empty for
+// games that are not yet decided. Resources are given by any number of
+// resource_title and resource_url column pairs.
+func readImportCSV(r io.Reader, name string) ([]importRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: reading header: %w", name, err)
+	}
+
+	columns := make(map[string]int)
+	var resourceTitles, resourceURLs []int
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		switch column {
+		case "resource_title":
+			resourceTitles = append(resourceTitles, i)
+		case "resource_url":
+			resourceURLs = append(resourceURLs, i)
+		default:
+			columns[column] = i
+		}
+	}
+
+	for _, required := range []string{"league", "season", "date", "home", "away"} {
+		if _, found := columns[required]; !found {
+			return nil, fmt.Errorf("%s: missing column \"%s\"", name, required)
+		}
+	}
+	if len(resourceTitles) != len(resourceURLs) {
+		return nil, fmt.Errorf("%s: resource_title and resource_url columns must be paired", name)
+	}
+
+	// Rows that cannot be read are kept with their error, so they are
+	// reported along with any that fail to resolve
+	var records []importRecord
+	for line := 2; ; line++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		source := fmt.Sprintf("%s:%d", name, line)
+		if err != nil {
+			records = append(records, importRecord{source: source, err: fmt.Errorf("%s: %w", source, err)})
+			continue
+		}
+
+		field := func(column string) string {
+			if i, found := columns[column]; found && i < len(fields) {
+				return strings.TrimSpace(fields[i])
+			}
+			return ""
+		}
+
+		record := importRecord{
+			League: field("league"),
+			Type:   field("type"),
+			Date:   field("date"),
+			Home:   field("home"),
+			Away:   field("away"),
+			Title:  field("title"),
+			Venue:  field("venue"),
+			Status: field("status"),
+			Time:   field("time"),
+			source: source,
+		}
+
+		numbers := []struct {
+			column string
+			dest   *int
+		}{
+			{"season", &record.Season},
+			{"home_score", &record.HomeScore},
+			{"away_score", &record.AwayScore},
+		}
+		var convErr error
+		for _, n := range numbers {
+			if n.column != "season" && field(n.column) == "" {
+				continue
+			}
+			if *n.dest, err = strconv.Atoi(field(n.column)); err != nil && convErr == nil {
+				convErr = fmt.Errorf("%s: invalid %s \"%s\"", source, n.column, field(n.column))
+			}
+		}
+		if convErr != nil {
+			records = append(records, importRecord{source: source, err: convErr})
+			continue
+		}
+
+		for i := range resourceTitles {
+			title, url := "", ""
+			if resourceTitles[i] < len(fields) {
+				title = strings.TrimSpace(fields[resourceTitles[i]])
+			}
+			if resourceURLs[i] < len(fields) {
+				url = strings.TrimSpace(fields[resourceURLs[i]])
+			}
+			if title != "" || url != "" {
+				record.Resources = append(record.Resources, importResource{title, url})
+			}
+		}
+
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// readImportJSON reads games from a JSON array of objects keyed like
+// the columns of the CSV format, with resources as an array of
+// {"title", "url"} objects
+func readImportJSON(r io.Reader, name string) ([]importRecord, error) {
+	var records []importRecord
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	for i := range records {
+		records[i].source = fmt.Sprintf("%s[%d]", name, i)
+	}
+	return records, nil
+}
+
+// importResolver resolves the leagues, seasons and clubs named in
+// import records, caching each lookup
+type importResolver struct {
+	cli     CLI
+	seasons map[string]Season
+	clubs   map[int][]Club
+}
+
+func newImportResolver(cli CLI) *importResolver {
+	return &importResolver{
+		cli:     cli,
+		seasons: make(map[string]Season),
+		clubs:   make(map[int][]Club),
+	}
+}
+
+func (ir *importResolver) season(code string, year int, seasonType string) (Season, error) {
+	key := strings.ToUpper(fmt.Sprintf("%s/%d/%s", code, year, seasonType))
+	if season, found := ir.seasons[key]; found {
+		return season, nil
+	}
+
+	season, err := ir.cli.findSeason(code, year, seasonType)
+	if err != nil {
+		return season, err
+	}
+	ir.seasons[key] = season
+	return season, nil
+}
+
+// club finds the club of season matching name, either by its full
+// "Represents Nickname" name or by a nickname unique to the season
+func (ir *importResolver) club(season Season, name string) (Club, error) {
+	clubs, found := ir.clubs[season.ID]
+	if !found {
+		var err error
+		if clubs, err = ir.cli.store.clubsBySeason(season); err != nil {
+			return Club{}, err
+		}
+		ir.clubs[season.ID] = clubs
+	}
+
+	var byNickname []Club
+	for _, club := range clubs {
+		if strings.EqualFold(fmt.Sprintf("%s %s", club.Represents, club.Nickname), name) {
+			return club, nil
+		}
+		if strings.EqualFold(club.Nickname, name) {
+			byNickname = append(byNickname, club)
+		}
+	}
+
+	switch len(byNickname) {
+	case 1:
+		return byNickname[0], nil
+	case 0:
+		return Club{}, fmt.Errorf("no club \"%s\" in the %d %s %s",
+			name, season.Year, season.League.Code, season.Type)
+	default:
+		return Club{}, fmt.Errorf("club \"%s\" is ambiguous in the %d %s %s",
+			name, season.Year, season.League.Code, season.Type)
+	}
+}
+
+func (ir *importResolver) resolve(record importRecord) (importRow, error) {
+	if record.Type == "" {
+		record.Type = "Season"
+	}
+	if record.Status == "" {
+		record.Status = statusFinal
+	}
+	row := importRow{record: record}
+
+	if err := validDate(record.Date); err != nil {
+		return row, err
+	}
+	if record.Time != "" {
+		if err := validTime(record.Time); err != nil {
+			return row, err
+		}
+	}
+	if err := validStatus(record.Status); err != nil {
+		return row, err
+	}
+	if record.HomeScore < 0 || record.AwayScore < 0 {
+		return row, errors.New("scores cannot be negative")
+	}
+	if !(Game{Status: record.Status}).Decided() && (record.HomeScore != 0 || record.AwayScore != 0) {
+		return row, fmt.Errorf("a %s game has no score", record.Status)
+	}
+	if err := validLength("title", record.Title, 0, 128); err != nil {
+		return row, err
+	}
+	if err := validLength("venue", record.Venue, 0, 128); err != nil {
+		return row, err
+	}
+	for _, r := range record.Resources {
+		if err := validLength("resource title", r.Title, 1, 128); err != nil {
+			return row, err
+		}
+		if err := validLength("resource url", r.URL, 1, 256); err != nil {
+			return row, err
+		}
+	}
+
+	var err error
+	if row.season, err = ir.season(record.League, record.Season, record.Type); err != nil {
+		return row, err
+	}
+	if row.home, err = ir.club(row.season, record.Home); err != nil {
+		return row, err
+	}
+	if row.away, err = ir.club(row.season, record.Away); err != nil {
+		return row, err
+	}
+	if row.home.ID == row.away.ID {
+		return row, errors.New("home and away clubs must differ")
+	}
+
+	return row, nil
+}
+
+// importGames resolves every record and, only if all of them were read
+// and resolve, creates their games and resources in a single
+// transaction. Otherwise every failed record is reported, in order. Pages for
+// the new games are generated along with the indices they appear in.
+func (cli CLI) importGames(records []importRecord) ([]Game, error) {
+	resolver := newImportResolver(cli)
+	rows := make([]importRow, 0, len(records))
+	var errs importErrors
+	for _, record := range records {
+		if record.err != nil {
+			errs = append(errs, record.err)
+			continue
+		}
+		row, err := resolver.resolve(record)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", record.source, err))
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	games := make([]Game, len(rows))
+	resources := make([][]Resource, len(rows))
+	err := cli.store.transaction(func(tx store) error {
+		for i, row := range rows {
+			r := row.record
+			game, err := tx.createGame(row.season, r.Date, row.home, r.HomeScore,
+				row.away, r.AwayScore, r.Title, r.Venue, r.Status, r.Time)
+			if err != nil {
+				return fmt.Errorf("%s: %w", r.source, err)
+			}
+			games[i] = game
+
+			for _, ir := range r.Resources {
+				resource, err := tx.createResource(game, ir.Title, ir.URL)
+				if err != nil {
+					return fmt.Errorf("%s: %w", r.source, err)
+				}
+				resources[i] = append(resources[i], resource)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dirty := newDirtyPages()
+	for i, game := range games {
+		if err := cli.renderGamePage(game, resources[i]); err != nil {
+			return games, err
+		}
+
+		dirty.addGame(game)
+	}
+	cli.regenerate(dirty)
+
+	return games, nil
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// periodLabel is the column heading of period n in a line score of
+// sport: its number in regulation, then "OT", "2OT" and so on
+func periodLabel(sport Sport, n int) string {
+	if n <= sport.Periods || sport.Periods == 0 || sport.OvertimeName == "" {
+		return strconv.Itoa(n)
+	}
+	if extra := n - sport.Periods; extra > 1 {
+		return fmt.Sprintf("%d%s", extra, sport.OvertimeName)
+	}
+	return sport.OvertimeName
+}
+
+// periodTitle is the full name of period n, e.g. "Quarter 2" or "OT"
+func periodTitle(sport Sport, n int) string {
+	if n > sport.Periods && sport.Periods > 0 && sport.OvertimeName != "" {
+		return periodLabel(sport, n)
+	}
+	return fmt.Sprintf("%s %d", sport.PeriodName, n)
+}
+
+// periodTotals sums the home and away scores of periods
+func periodTotals(periods []Period) (int, int) {
+	home, away := 0, 0
+	for _, period := range periods {
+		home += period.HomeScore
+		away += period.AwayScore
+	}
+	return home, away
+}
+
+// checkPeriods verifies that periods are numbered from 1 in order and,
+// if there are any, that they add up to the final score of game. A
+// game that has not been decided can have neither.
+func checkPeriods(game Game, periods []Period) error {
+	if !game.Decided() && (game.HomeScore != 0 || game.AwayScore != 0 || len(periods) > 0) {
+		return fmt.Errorf("a %s game has no score", game.Status)
+	}
+	for i, period := range periods {
+		if period.Number != i+1 {
+			return fmt.Errorf("period %d out of order, expected period %d", period.Number, i+1)
+		}
+		if period.HomeScore < 0 || period.AwayScore < 0 {
+			return fmt.Errorf("period %d: scores cannot be negative", period.Number)
+		}
+	}
+	if len(periods) == 0 {
+		return nil
+	}
+
+	home, away := periodTotals(periods)
+	if home != game.HomeScore || away != game.AwayScore {
+		return fmt.Errorf("periods total %d-%d, but the final score of game %d is %d-%d",
+			home, away, game.ID, game.HomeScore, game.AwayScore)
+	}
+	return nil
+}
+
+// lineScoreColumn is one period of a line score as shown on a page
+type lineScoreColumn struct {
+	Period
+	Label string
+	Title string
+}
+
+// lineScore is a game's scoring by period, ready for the game template
+type lineScore struct {
+	Columns []lineScoreColumn
+}
+
+func newLineScore(sport Sport, periods []Period) lineScore {
+	line := lineScore{Columns: make([]lineScoreColumn, len(periods))}
+	for i, period := range periods {
+		line.Columns[i] = lineScoreColumn{
+			Period: period,
+			Label:  periodLabel(sport, period.Number),
+			Title:  periodTitle(sport, period.Number),
+		}
+	}
+	return line
+}
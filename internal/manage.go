@@ -0,0 +1,435 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+func validLeagueCode(code string) error {
+	if valid, _ := regexp.MatchString("^[A-Za-z0-9]{1,8}$", code); !valid {
+		return fmt.Errorf("invalid league code \"%s\", expected 1 to 8 letters or digits", code)
+	}
+	return nil
+}
+
+func validClub(represents, nickname string) error {
+	if err := validLength("represents", represents, 1, 128); err != nil {
+		return err
+	}
+	return validLength("nickname", nickname, 0, 128)
+}
+
+func clubName(club Club) string {
+	return fmt.Sprintf("%s %s", club.Represents, club.Nickname)
+}
+
+// findSport looks up the sport with the given name
+func (cli CLI) findSport(name string) (Sport, error) {
+	sports, err := cli.store.sports()
+	if err != nil {
+		return Sport{}, err
+	}
+	for _, sport := range sports {
+		if strings.EqualFold(sport.Name, name) {
+			return sport, nil
+		}
+	}
+	return Sport{}, fmt.Errorf("no sport \"%s\"", name)
+}
+
+// clubIterations returns every iteration of the club with the given
+// ID, oldest first
+func (cli CLI) clubIterations(id int) ([]Club, error) {
+	clubs, err := cli.store.clubs()
+	if err != nil {
+		return nil, err
+	}
+
+	var iterations []Club
+	for _, club := range clubs {
+		if club.ID == id {
+			iterations = append(iterations, club)
+		}
+	}
+	if len(iterations) == 0 {
+		return nil, fmt.Errorf("no club %d", id)
+	}
+	return iterations, nil
+}
+
+// findClubIteration looks up the given iteration of a club, or its
+// latest iteration if iteration is 0
+func (cli CLI) findClubIteration(id, iteration int) (Club, error) {
+	iterations, err := cli.clubIterations(id)
+	if err != nil {
+		return Club{}, err
+	}
+	if iteration == 0 {
+		return iterations[len(iterations)-1], nil
+	}
+	for _, club := range iterations {
+		if club.Iteration == iteration {
+			return club, nil
+		}
+	}
+	return Club{}, fmt.Errorf("club %d has no iteration %d", id, iteration)
+}
+
+// activeLeagues returns the leagues club is an active member of,
+// at any iteration
+func (cli CLI) activeLeagues(club Club) ([]League, error) {
+	leagues, err := cli.store.leagues()
+	if err != nil {
+		return nil, err
+	}
+
+	var active []League
+	for _, league := range leagues {
+		clubs, err := cli.store.clubsByLeague(league, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range clubs {
+			if c.ID == club.ID {
+				active = append(active, league)
+			}
+		}
+	}
+	return active, nil
+}
+
+// addClubIteration creates the next iteration of club and makes it
+// the active iteration in each of the given leagues
+func (cli CLI) addClubIteration(club Club, represents, nickname string, leagues []League) (Club, error) {
+	var next Club
+	err := cli.store.transaction(func(tx store) error {
+		var err error
+		if next, err = tx.createClubIteration(club, represents, nickname); err != nil {
+			return err
+		}
+		for _, league := range leagues {
+			if err = tx.setActiveClub(league, next); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return next, err
+}
+
+func (cli CLI) manage() {
+	actions := []string{"Add Sport", "Edit Sport Periods", "Add Stat", "Add League", "Add Club",
+		"Add Club Iteration", "Add Club to League", "Remove Club from League", "Add Player",
+		"Add Player to Roster"}
+	funcs := []func(){cli.promptAddSport, cli.promptEditSport, cli.promptAddStat, cli.promptAddLeague,
+		cli.promptAddClub, cli.promptAddClubIteration, cli.promptAddLeagueClub, cli.promptRemoveLeagueClub,
+		cli.promptAddPlayer, cli.promptAddRosterPlayer}
+	funcs[promptList(actions)]()
+}
+
+func (cli CLI) promptSports() Sport {
+	sports, err := cli.store.sports()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	if len(sports) == 0 {
+		fmt.Println("No sports")
+		os.Exit(0)
+	}
+
+	sportsStr := make([]string, len(sports))
+	for i, sport := range sports {
+		sportsStr[i] = sport.Name
+	}
+
+	fmt.Println("Select sport:")
+	return sports[promptList(sportsStr)]
+}
+
+// promptClub asks for a club ID and shows the club's iterations,
+// returning the latest
+func (cli CLI) promptClub() Club {
+	for {
+		iterations, err := cli.clubIterations(promptInt("club ID"))
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		for _, club := range iterations {
+			fmt.Printf("Iteration %d: %s\n", club.Iteration, clubName(club))
+		}
+		return iterations[len(iterations)-1]
+	}
+}
+
+// promptSportPeriods asks how games of sport divide into periods
+func promptSportPeriods(sport *Sport) {
+	sport.Periods = promptInt("number of periods in regulation (0 for no line scores)")
+	for sport.Periods < 0 {
+		sport.Periods = promptInt("number of periods in regulation (0 for no line scores)")
+	}
+	sport.PeriodName = "Period"
+	sport.OvertimeName = "OT"
+	if sport.Periods > 0 {
+		sport.PeriodName = promptString("period name, e.g. Quarter", 1, 32)
+		sport.OvertimeName = promptString("overtime name, e.g. OT (empty to number them on)", 0, 32)
+	}
+}
+
+func printSportPeriods(sport Sport) {
+	fmt.Println("Periods:", sport.Periods)
+	if sport.Periods > 0 {
+		fmt.Println("Period name:", sport.PeriodName)
+		fmt.Println("Overtime name:", sport.OvertimeName)
+	}
+}
+
+func (cli CLI) promptAddSport() {
+	sport := Sport{Name: promptString("sport name", 1, 64)}
+	promptSportPeriods(&sport)
+
+	fmt.Println("Sport:", sport.Name)
+	printSportPeriods(sport)
+	if !promptBool("Save") {
+		return
+	}
+
+	sport, err := cli.store.createSport(sport.Name, sport.Periods, sport.PeriodName, sport.OvertimeName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Added sport %d: %s\n", sport.ID, sport.Name)
+}
+
+func (cli CLI) promptEditSport() {
+	sport := cli.promptSports()
+	printSportPeriods(sport)
+	promptSportPeriods(&sport)
+
+	fmt.Println("Sport:", sport.Name)
+	printSportPeriods(sport)
+	if !promptBool("Save") {
+		return
+	}
+
+	sport, err := cli.store.editSport(sport)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Edited sport %d: %s\n", sport.ID, sport.Name)
+}
+
+func (cli CLI) promptAddLeague() {
+	sport := cli.promptSports()
+	code := promptString("league code", 1, 8)
+	for validLeagueCode(code) != nil {
+		fmt.Println(validLeagueCode(code))
+		code = promptString("league code", 1, 8)
+	}
+	name := promptString("league name", 1, 128)
+
+	fmt.Println("Sport:", sport.Name)
+	fmt.Println("Code:", strings.ToUpper(code))
+	fmt.Println("Name:", name)
+	if !promptBool("Save") {
+		return
+	}
+
+	league, err := cli.store.createLeague(code, name, sport)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Added league %s: %s %s\n", league.Code, league.Name, league.Sport)
+}
+
+func (cli CLI) promptAddClub() {
+	represents := promptString("represents", 1, 128)
+	nickname := promptString("nickname", 0, 128)
+
+	var leagues []League
+	for promptBool("Make the club active in a league") {
+		leagues = append(leagues, cli.promptLeagues())
+	}
+
+	fmt.Println("Club:", represents, nickname)
+	for _, league := range leagues {
+		fmt.Println("Active in:", league.Code)
+	}
+	if !promptBool("Save") {
+		return
+	}
+
+	var club Club
+	err := cli.store.transaction(func(tx store) error {
+		var err error
+		if club, err = tx.createClub(represents, nickname); err != nil {
+			return err
+		}
+		for _, league := range leagues {
+			if err = tx.setActiveClub(league, club); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Added club %d: %s\n", club.ID, clubName(club))
+}
+
+func (cli CLI) promptAddClubIteration() {
+	club := cli.promptClub()
+	represents := promptString("represents", 1, 128)
+	nickname := promptString("nickname", 0, 128)
+
+	active, err := cli.activeLeagues(club)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	var leagues []League
+	for _, league := range active {
+		if promptBool(fmt.Sprintf("Make %s %s the active iteration in %s", represents, nickname, league.Code)) {
+			leagues = append(leagues, league)
+		}
+	}
+
+	fmt.Printf("Club %d: %s -> %s %s\n", club.ID, clubName(club), represents, nickname)
+	for _, league := range leagues {
+		fmt.Println("Active in:", league.Code)
+	}
+	if !promptBool("Save") {
+		return
+	}
+
+	next, err := cli.addClubIteration(club, represents, nickname, leagues)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Added iteration %d of club %d: %s\n", next.Iteration, next.ID, clubName(next))
+}
+
+func (cli CLI) promptAddLeagueClub() {
+	league := cli.promptLeagues()
+	club := cli.promptClub()
+
+	fmt.Printf("Make %s active in %s\n", clubName(club), league.Code)
+	if !promptBool("Save") {
+		return
+	}
+
+	if err := cli.store.setActiveClub(league, club); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (cli CLI) promptRemoveLeagueClub() {
+	league := cli.promptLeagues()
+	clubs, err := cli.store.clubsByLeague(league, true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	if len(clubs) == 0 {
+		fmt.Println("No active clubs")
+		return
+	}
+
+	clubsStr := make([]string, len(clubs))
+	for i, club := range clubs {
+		clubsStr[i] = clubName(club)
+	}
+
+	fmt.Println("Select club to remove:")
+	club := clubs[promptList(clubsStr)]
+
+	fmt.Printf("Remove %s from %s\n", clubName(club), league.Code)
+	if !promptBool("Save") {
+		return
+	}
+
+	if err := cli.store.removeActiveClub(league, club); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (cli CLI) promptAddStat() {
+	sport := cli.promptSports()
+	code := strings.ToUpper(promptString("stat code, e.g. PTS", 1, 8))
+	name := promptString("stat name, e.g. Points", 1, 64)
+
+	fmt.Println("Sport:", sport.Name)
+	fmt.Println("Code:", code)
+	fmt.Println("Name:", name)
+	if !promptBool("Save") {
+		return
+	}
+
+	stat, err := cli.store.createStat(sport, code, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Added %s stat %d: %s (%s)\n", sport.Name, stat.ID, stat.Code, stat.Name)
+}
+
+func (cli CLI) promptAddPlayer() {
+	name := promptString("player name", 1, 128)
+
+	fmt.Println("Player:", name)
+	if !promptBool("Save") {
+		return
+	}
+
+	player, err := cli.store.createPlayer(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Added player %d: %s\n", player.ID, player.Name)
+
+	if err := cli.writePlayerPage(player); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (cli CLI) promptAddRosterPlayer() {
+	league := cli.promptLeagues()
+	season := cli.promptSeasons(league)
+	club := cli.promptClubs(season)
+
+	var player Player
+	for {
+		var err error
+		if player, err = cli.store.player(promptInt("player ID")); err == nil {
+			break
+		}
+		fmt.Println(err)
+	}
+
+	fmt.Printf("Add %s to the %d %s %s roster of %s\n",
+		player.Name, season.Year, league.Code, season.Type, clubName(club))
+	if !promptBool("Save") {
+		return
+	}
+
+	if err := cli.store.addRosterPlayer(season, club, player); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,271 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// manifestEntry records the input a page was last written from, and
+// the size and modification time of the page it produced
+type manifestEntry struct {
+	Hash     string    `json:"hash"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// manifest tracks every page written under the site root so that a
+// page whose input is unchanged need not be rendered again. Input
+// hashes cover the generator version, so a new template or binary
+// rewrites every page. It is safe for concurrent use.
+type manifest struct {
+	path    string
+	version string
+	mu      sync.Mutex
+	Pages   map[string]manifestEntry `json:"pages"`
+
+	// seen holds the pages written or skipped during this run
+	seen    map[string]bool
+	written int
+	skipped int
+	removed int
+}
+
+// loadManifest reads the manifest at path. A missing or unreadable
+// manifest is treated as empty, so every page is written.
+func loadManifest(path, version string) *manifest {
+	m := &manifest{path: path, version: version}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, m)
+	}
+	if m.Pages == nil {
+		m.Pages = make(map[string]manifestEntry)
+	}
+	m.seen = make(map[string]bool)
+	return m
+}
+
+// startRun begins a run over the whole site, clearing the pages seen
+// and the counts of any earlier run
+func (m *manifest) startRun() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seen = make(map[string]bool)
+	m.written, m.skipped, m.removed = 0, 0, 0
+}
+
+func (m *manifest) save() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.path, data, 0644)
+}
+
+// reset forgets all that is known of the pages and saves the manifest
+// with just those listed, so each is written afresh on the next run
+// and any the site no longer has is removed
+func (m *manifest) reset(pages []string) error {
+	m.mu.Lock()
+	m.Pages = make(map[string]manifestEntry, len(pages))
+	for _, sitePath := range pages {
+		m.Pages[sitePath] = manifestEntry{}
+	}
+	m.mu.Unlock()
+	return m.save()
+}
+
+// setVersion changes the generator version hashed with each page's
+// input, such as when the templates are reloaded
+func (m *manifest) setVersion(version string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.version = version
+}
+
+// hash identifies input, which must encode as JSON, together with
+// the generator version
+func (m *manifest) hash(input any) (string, error) {
+	m.mu.Lock()
+	version := m.version
+	m.mu.Unlock()
+
+	h := sha256.New()
+	io.WriteString(h, version)
+	if err := json.NewEncoder(h).Encode(input); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// unchanged reports whether the page at sitePath, written to file,
+// was last written from the input with hash and has not been touched
+// since, along with its gzipped copy
+func (m *manifest) unchanged(sitePath, file, hash string) bool {
+	m.mu.Lock()
+	entry, found := m.Pages[sitePath]
+	m.mu.Unlock()
+	if !found || entry.Hash != hash {
+		return false
+	}
+	info, err := os.Stat(file)
+	if err != nil || info.Size() != entry.Size || !info.ModTime().Equal(entry.Modified) {
+		return false
+	}
+	info, err = os.Stat(file + ".gz")
+	return err == nil && info.Mode().IsRegular()
+}
+
+// record notes that the page at sitePath was written to file from the
+// input with hash
+func (m *manifest) record(sitePath, file, hash string) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Pages[sitePath] = manifestEntry{hash, info.Size(), info.ModTime()}
+	m.seen[sitePath] = true
+	m.written++
+	return nil
+}
+
+// wouldWrite notes that the page at sitePath would have been written
+// in a dry run
+func (m *manifest) wouldWrite(sitePath string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seen[sitePath] = true
+	m.written++
+}
+
+func (m *manifest) skip(sitePath string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seen[sitePath] = true
+	m.skipped++
+}
+
+func (m *manifest) forget(sitePath string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, found := m.Pages[sitePath]; found {
+		delete(m.Pages, sitePath)
+		m.removed++
+	}
+}
+
+// counts returns how many pages were written, skipped and removed
+// during this run
+func (m *manifest) counts() (int, int, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.written, m.skipped, m.removed
+}
+
+func (m *manifest) changed() bool {
+	written, _, removed := m.counts()
+	return written+removed > 0
+}
+
+// pages lists every page in the manifest
+func (m *manifest) pages() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	paths := make([]string, 0, len(m.Pages))
+	for sitePath := range m.Pages {
+		paths = append(paths, sitePath)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// unseen lists the pages in the manifest that were neither written nor
+// skipped during this run
+func (m *manifest) unseen() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var paths []string
+	for sitePath := range m.Pages {
+		if !m.seen[sitePath] {
+			paths = append(paths, sitePath)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// buildVersion identifies the recap binary. It may be set when
+// building with -ldflags "-X recap/internal.buildVersion=...", and
+// otherwise comes from the build information.
+var buildVersion string
+
+// binaryVersion is buildVersion, or the module version and VCS revision
+// the binary was built from. A binary built from a modified tree is
+// told apart by its size and modification time.
+var binaryVersion = func() string {
+	if buildVersion != "" {
+		return buildVersion
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return executableStamp()
+	}
+
+	version := fmt.Sprintf("%s %s %s", info.GoVersion, info.Main.Version, info.Main.Sum)
+	modified := true
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			version = fmt.Sprintf("%s %s", version, setting.Value)
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if modified && info.Main.Sum == "" {
+		version = fmt.Sprintf("%s %s", version, executableStamp())
+	}
+	return version
+}()
+
+// executableStamp is the size and modification time of the running
+// executable, which change whenever it is rebuilt
+func executableStamp() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	info, err := os.Stat(exe)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d %d", info.Size(), info.ModTime().UnixNano())
+}
+
+// generatorVersion hashes the page templates and the version of the
+// binary, which together decide how any input renders
+func generatorVersion(templates map[string]string) string {
+	h := sha256.New()
+	io.WriteString(h, binaryVersion)
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// Lengths keep a name from running into its template
+	for _, name := range names {
+		fmt.Fprintf(h, "%d:%s%d:%s", len(name), name, len(templates[name]), templates[name])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,166 @@
+package internal
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeTestPage writes the page at sitePath from input, reporting
+// whether it had to be rendered
+func writeTestPage(t *testing.T, dg documentGenerator, sitePath, input string) bool {
+	t.Helper()
+	rendered := false
+	err := dg.page(sitePath, input, func(w io.Writer) error {
+		rendered = true
+		_, err := io.WriteString(w, "<p>"+input+"</p>")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rendered
+}
+
+func TestPageSkippedWhenUnchanged(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(t *testing.T, page string)
+		version  string
+		input    string
+		rendered bool
+	}{
+		{"same input", nil, "v1", "a", false},
+		{"new input", nil, "v1", "b", true},
+		{"new generator", nil, "v2", "a", true},
+		{"page edited", func(t *testing.T, page string) {
+			if err := os.WriteFile(page, []byte("<p>edited</p>"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}, "v1", "a", true},
+		{"page touched", func(t *testing.T, page string) {
+			later := time.Now().Add(time.Hour)
+			if err := os.Chtimes(page, later, later); err != nil {
+				t.Fatal(err)
+			}
+		}, "v1", "a", true},
+		{"gzipped page removed", func(t *testing.T, page string) {
+			if err := os.Remove(page + ".gz"); err != nil {
+				t.Fatal(err)
+			}
+		}, "v1", "a", true},
+		{"manifest removed", func(t *testing.T, page string) {
+			if err := os.Remove(filepath.Join(filepath.Dir(page), "manifest.json")); err != nil {
+				t.Fatal(err)
+			}
+		}, "v1", "a", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			manifestPath := filepath.Join(dir, "manifest.json")
+			dg := documentGenerator{staticPath: dir, manifest: loadManifest(manifestPath, "v1")}
+			if !writeTestPage(t, dg, "index.html", "a") {
+				t.Fatal("first write skipped")
+			}
+			if err := dg.saveManifest(); err != nil {
+				t.Fatal(err)
+			}
+
+			if test.change != nil {
+				test.change(t, filepath.Join(dir, "index.html"))
+			}
+			dg.manifest = loadManifest(manifestPath, test.version)
+			if got := writeTestPage(t, dg, "index.html", test.input); got != test.rendered {
+				t.Errorf("got rendered %v, want %v", got, test.rendered)
+			}
+		})
+	}
+}
+
+func TestRemoveStalePages(t *testing.T) {
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "manifest.json")
+	dg := documentGenerator{staticPath: dir, manifest: loadManifest(manifestPath, "v1")}
+	writeTestPage(t, dg, "kept.html", "kept")
+	writeTestPage(t, dg, "stale.html", "stale")
+	if err := dg.saveManifest(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next run writes only one of the pages
+	dg.manifest = loadManifest(manifestPath, "v1")
+	writeTestPage(t, dg, "kept.html", "kept")
+	if err := dg.removeStalePages(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{"kept.html", "kept.html.gz"} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Errorf("%s: %v", file, err)
+		}
+	}
+	for _, file := range []string{"stale.html", "stale.html.gz"} {
+		if _, err := os.Stat(filepath.Join(dir, file)); !os.IsNotExist(err) {
+			t.Errorf("%s not removed", file)
+		}
+	}
+	if _, found := dg.manifest.Pages["stale.html"]; found {
+		t.Error("stale page still in the manifest")
+	}
+}
+
+func TestGeneratorVersion(t *testing.T) {
+	base := map[string]string{"game.tmpl": "{{ .Title }}", "index.tmpl": "{{ .Sidebar }}"}
+	tests := []struct {
+		name      string
+		templates map[string]string
+		same      bool
+	}{
+		{"same templates", map[string]string{"index.tmpl": "{{ .Sidebar }}", "game.tmpl": "{{ .Title }}"}, true},
+		{"template edited", map[string]string{"game.tmpl": "{{ .Venue }}", "index.tmpl": "{{ .Sidebar }}"}, false},
+		{"template renamed", map[string]string{"games.tmpl": "{{ .Title }}", "index.tmpl": "{{ .Sidebar }}"}, false},
+		{"template added", map[string]string{"game.tmpl": "{{ .Title }}", "index.tmpl": "{{ .Sidebar }}", "x.tmpl": ""}, false},
+		{"content moved into name", map[string]string{"game.tmpl{{": " .Title }}", "index.tmpl": "{{ .Sidebar }}"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := generatorVersion(test.templates) == generatorVersion(base); got != test.same {
+				t.Errorf("got same version %v, want %v", got, test.same)
+			}
+		})
+	}
+}
+
+func TestManifestSetVersion(t *testing.T) {
+	m := loadManifest(filepath.Join(t.TempDir(), "manifest.json"), "v1")
+	before, err := m.hash("input")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Templates are reloaded while pages are still being hashed
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.hash("input")
+		}()
+	}
+	m.setVersion("v2")
+	wg.Wait()
+
+	after, err := m.hash("input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after == before {
+		t.Error("hash unchanged by a new generator version")
+	}
+}
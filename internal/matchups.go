@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+)
+
+// matchupClubs orders the clubs of game by ID, the order they take in
+// the path and summary of their matchup
+func matchupClubs(game Game) (Club, Club) {
+	if game.Away.ID < game.Home.ID {
+		return game.Away, game.Home
+	}
+	return game.Home, game.Away
+}
+
+// streak is a run of consecutive wins by one club
+type streak struct {
+	Length int
+	From   string
+	To     string
+}
+
+// matchup summarizes every game between two clubs, from the
+// perspective of A, the club with the lower ID
+type matchup struct {
+	A       Club
+	B       Club
+	Series  record
+	margin  int
+	StreakA streak
+	StreakB streak
+	Games   []Game
+}
+
+// newMatchup summarizes games, which are ordered newest first as
+// returned by store.games. The clubs are named as they were in the
+// most recent game. Only final games count toward the series, margin
+// and streaks, but every game is listed.
+func newMatchup(games []Game) matchup {
+	var m matchup
+	if len(games) == 0 {
+		return m
+	}
+	m.A, m.B = matchupClubs(games[0])
+	m.Games = games
+
+	var current streak
+	var currentClub int
+	for i := len(games) - 1; i >= 0; i-- {
+		game := games[i]
+		if !game.Final() {
+			continue
+		}
+		scoreA, scoreB := game.HomeScore, game.AwayScore
+		if game.Home.ID != m.A.ID {
+			scoreA, scoreB = scoreB, scoreA
+		}
+		m.Series.add(scoreA, scoreB)
+		m.margin += scoreA - scoreB
+
+		winner := 0
+		switch {
+		case scoreA > scoreB:
+			winner = m.A.ID
+		case scoreB > scoreA:
+			winner = m.B.ID
+		}
+
+		if winner == 0 || winner != currentClub {
+			current = streak{From: game.Date}
+		}
+		currentClub = winner
+		if winner == 0 {
+			continue
+		}
+		current.Length++
+		current.To = game.Date
+
+		if winner == m.A.ID && current.Length > m.StreakA.Length {
+			m.StreakA = current
+		} else if winner == m.B.ID && current.Length > m.StreakB.Length {
+			m.StreakB = current
+		}
+	}
+
+	return m
+}
+
+// Leader describes who leads the all-time series
+func (m matchup) Leader() string {
+	var s string
+	switch {
+	case m.Series.Wins > m.Series.Losses:
+		s = fmt.Sprintf("%s lead %d-%d", m.A.Nickname, m.Series.Wins, m.Series.Losses)
+	case m.Series.Losses > m.Series.Wins:
+		s = fmt.Sprintf("%s lead %d-%d", m.B.Nickname, m.Series.Losses, m.Series.Wins)
+	default:
+		s = fmt.Sprintf("Tied %d-%d", m.Series.Wins, m.Series.Losses)
+	}
+	if m.Series.Ties > 0 {
+		s = fmt.Sprintf("%s-%d", s, m.Series.Ties)
+	}
+	return s
+}
+
+// AverageMargin describes the mean margin of the games, to one decimal
+func (m matchup) AverageMargin() string {
+	if m.Series.played() == 0 {
+		return "-"
+	}
+	average := float64(m.margin) / float64(m.Series.played())
+	switch {
+	case average > 0:
+		return fmt.Sprintf("%s by %.1f", m.A.Nickname, average)
+	case average < 0:
+		return fmt.Sprintf("%s by %.1f", m.B.Nickname, math.Abs(average))
+	default:
+		return "Even"
+	}
+}
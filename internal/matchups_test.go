@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestNewMatchup(t *testing.T) {
+	hawks := Club{ID: 1, Represents: "Atlanta", Nickname: "Hawks"}
+	celtics := Club{ID: 2, Represents: "Boston", Nickname: "Celtics"}
+	renamed := Club{ID: 1, Iteration: 2, Represents: "St. Louis", Nickname: "Hawks"}
+
+	tests := []struct {
+		name    string
+		games   []Game // newest first, as store.games returns them
+		a, b    Club
+		leader  string
+		margin  string
+		streakA streak
+		streakB streak
+	}{
+		{
+			name:   "no games",
+			leader: "Tied 0-0",
+			margin: "-",
+		},
+		{
+			name: "A is the lower ID, home or away",
+			games: []Game{
+				{ID: 2, Date: "2022-02-01", Home: celtics, HomeScore: 110, Away: hawks, AwayScore: 100, Status: statusFinal},
+				{ID: 1, Date: "2022-01-01", Home: celtics, HomeScore: 96, Away: hawks, AwayScore: 90, Status: statusFinal},
+			},
+			a:       hawks,
+			b:       celtics,
+			leader:  "Celtics lead 2-0",
+			margin:  "Celtics by 8.0",
+			streakB: streak{Length: 2, From: "2022-01-01", To: "2022-02-01"},
+		},
+		{
+			name: "a tie ends a streak",
+			games: []Game{
+				{ID: 5, Date: "2022-05-01", Home: celtics, HomeScore: 70, Away: hawks, AwayScore: 75, Status: statusFinal},
+				{ID: 4, Date: "2022-04-01", Home: hawks, HomeScore: 88, Away: celtics, AwayScore: 88, Status: statusFinal},
+				{ID: 3, Date: "2022-03-01", Home: celtics, HomeScore: 80, Away: hawks, AwayScore: 70, Status: statusFinal},
+				{ID: 2, Date: "2022-02-01", Home: celtics, HomeScore: 95, Away: hawks, AwayScore: 90, Status: statusFinal},
+				{ID: 1, Date: "2022-01-01", Home: hawks, HomeScore: 100, Away: celtics, AwayScore: 90, Status: statusFinal},
+			},
+			a:       hawks,
+			b:       celtics,
+			leader:  "Tied 2-2-1",
+			margin:  "Even",
+			streakA: streak{Length: 1, From: "2022-01-01", To: "2022-01-01"},
+			streakB: streak{Length: 2, From: "2022-02-01", To: "2022-03-01"},
+		},
+		{
+			name: "undecided games listed but not counted",
+			games: []Game{
+				{ID: 3, Date: "2022-03-01", Home: hawks, Away: celtics, Status: statusScheduled},
+				{ID: 2, Date: "2022-02-01", Home: celtics, Away: hawks, Status: statusPostponed},
+				{ID: 1, Date: "2022-01-01", Home: hawks, HomeScore: 100, Away: celtics, AwayScore: 90, Status: statusFinal},
+			},
+			a:       hawks,
+			b:       celtics,
+			leader:  "Hawks lead 1-0",
+			margin:  "Hawks by 10.0",
+			streakA: streak{Length: 1, From: "2022-01-01", To: "2022-01-01"},
+		},
+		{
+			name: "forfeits listed but not counted",
+			games: []Game{
+				{ID: 2, Date: "2022-02-01", Home: celtics, HomeScore: 2, Away: hawks, Status: statusForfeit},
+				{ID: 1, Date: "2022-01-01", Home: hawks, HomeScore: 100, Away: celtics, AwayScore: 90, Status: statusFinal},
+			},
+			a:       hawks,
+			b:       celtics,
+			leader:  "Hawks lead 1-0",
+			margin:  "Hawks by 10.0",
+			streakA: streak{Length: 1, From: "2022-01-01", To: "2022-01-01"},
+		},
+		{
+			name: "longest streak kept",
+			games: []Game{
+				{ID: 4, Date: "2022-04-01", Home: hawks, HomeScore: 101, Away: celtics, AwayScore: 100, Status: statusFinal},
+				{ID: 3, Date: "2022-03-01", Home: celtics, HomeScore: 90, Away: hawks, AwayScore: 100, Status: statusFinal},
+				{ID: 2, Date: "2022-02-01", Home: hawks, HomeScore: 99, Away: celtics, AwayScore: 100, Status: statusFinal},
+				{ID: 1, Date: "2022-01-01", Home: hawks, HomeScore: 100, Away: celtics, AwayScore: 90, Status: statusFinal},
+			},
+			a:       hawks,
+			b:       celtics,
+			leader:  "Hawks lead 3-1",
+			margin:  "Hawks by 5.0",
+			streakA: streak{Length: 2, From: "2022-03-01", To: "2022-04-01"},
+			streakB: streak{Length: 1, From: "2022-02-01", To: "2022-02-01"},
+		},
+		{
+			name: "named as in the latest game",
+			games: []Game{
+				{ID: 2, Date: "2022-02-01", Home: renamed, HomeScore: 100, Away: celtics, AwayScore: 90, Status: statusFinal},
+				{ID: 1, Date: "1960-01-01", Home: celtics, HomeScore: 100, Away: hawks, AwayScore: 90, Status: statusFinal},
+			},
+			a:       renamed,
+			b:       celtics,
+			leader:  "Tied 1-1",
+			margin:  "Even",
+			streakA: streak{Length: 1, From: "2022-02-01", To: "2022-02-01"},
+			streakB: streak{Length: 1, From: "1960-01-01", To: "1960-01-01"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := newMatchup(test.games)
+			if m.A != test.a || m.B != test.b {
+				t.Errorf("got clubs %+v and %+v, want %+v and %+v", m.A, m.B, test.a, test.b)
+			}
+			if got := m.Leader(); got != test.leader {
+				t.Errorf("got leader %q, want %q", got, test.leader)
+			}
+			if got := m.AverageMargin(); got != test.margin {
+				t.Errorf("got margin %q, want %q", got, test.margin)
+			}
+			if m.StreakA != test.streakA || m.StreakB != test.streakB {
+				t.Errorf("got streaks %+v and %+v, want %+v and %+v", m.StreakA, m.StreakB, test.streakA, test.streakB)
+			}
+			if len(m.Games) != len(test.games) {
+				t.Errorf("listed %d games, want %d", len(m.Games), len(test.games))
+			}
+		})
+	}
+}
@@ -0,0 +1,1031 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// memoryStore is a store held entirely in memory. It enforces the
+// same constraints and returns rows in the same order as the SQL
+// backends, so it can stand in for a database in tests and dry runs.
+type memoryStore struct {
+	data *memoryData
+	inTx bool
+}
+
+type clubKey struct {
+	id        int
+	iteration int
+}
+
+type memorySeason struct {
+	id         int
+	league     string
+	year       int
+	seasonType string
+	exhibition bool
+}
+
+type memoryGame struct {
+	id        int
+	season    int
+	date      string
+	time      string
+	status    string
+	title     string
+	venue     string
+	home      int
+	homeScore int
+	away      int
+	awayScore int
+}
+
+type memoryResource struct {
+	Resource
+	game int
+}
+
+type memoryStat struct {
+	Stat
+	sport int
+}
+
+// rosterKey identifies a player on a club's roster for a season
+type rosterKey struct {
+	season int
+	club   int
+	player int
+}
+
+// statLineKey identifies a player's stat line in a game
+type statLineKey struct {
+	game   int
+	player int
+}
+
+// memoryData mirrors the tables of the SQL schema
+type memoryData struct {
+	sports        map[int]Sport
+	leagues       map[string]League
+	seasons       map[int]memorySeason
+	activeSeasons map[string]int
+	clubs         map[clubKey]Club
+	activeClubs   map[string]map[int]int
+	seasonClubs   map[int]map[int]int
+	games         map[int]memoryGame
+	resources     map[int]memoryResource
+	periods       map[int][]Period
+	players       map[int]Player
+	rosters       map[rosterKey]bool
+	stats         map[int]memoryStat
+	statLines     map[statLineKey]StatLine
+}
+
+func newMemoryStore() memoryStore {
+	return memoryStore{data: &memoryData{
+		sports:        make(map[int]Sport),
+		leagues:       make(map[string]League),
+		seasons:       make(map[int]memorySeason),
+		activeSeasons: make(map[string]int),
+		clubs:         make(map[clubKey]Club),
+		activeClubs:   make(map[string]map[int]int),
+		seasonClubs:   make(map[int]map[int]int),
+		games:         make(map[int]memoryGame),
+		resources:     make(map[int]memoryResource),
+		periods:       make(map[int][]Period),
+		players:       make(map[int]Player),
+		rosters:       make(map[rosterKey]bool),
+		stats:         make(map[int]memoryStat),
+		statLines:     make(map[statLineKey]StatLine),
+	}}
+}
+
+func (data *memoryData) clone() *memoryData {
+	c := newMemoryStore().data
+	for k, v := range data.sports {
+		c.sports[k] = v
+	}
+	for k, v := range data.leagues {
+		c.leagues[k] = v
+	}
+	for k, v := range data.seasons {
+		c.seasons[k] = v
+	}
+	for k, v := range data.activeSeasons {
+		c.activeSeasons[k] = v
+	}
+	for k, v := range data.clubs {
+		c.clubs[k] = v
+	}
+	for k, v := range data.activeClubs {
+		c.activeClubs[k] = make(map[int]int, len(v))
+		for id, iteration := range v {
+			c.activeClubs[k][id] = iteration
+		}
+	}
+	for k, v := range data.seasonClubs {
+		c.seasonClubs[k] = make(map[int]int, len(v))
+		for id, iteration := range v {
+			c.seasonClubs[k][id] = iteration
+		}
+	}
+	for k, v := range data.games {
+		c.games[k] = v
+	}
+	for k, v := range data.resources {
+		c.resources[k] = v
+	}
+	for k, v := range data.periods {
+		c.periods[k] = append([]Period(nil), v...)
+	}
+	for k, v := range data.players {
+		c.players[k] = v
+	}
+	for k, v := range data.rosters {
+		c.rosters[k] = v
+	}
+	for k, v := range data.stats {
+		c.stats[k] = v
+	}
+	for k, v := range data.statLines {
+		values := make(map[int]int, len(v.Values))
+		for id, value := range v.Values {
+			values[id] = value
+		}
+		v.Values = values
+		c.statLines[k] = v
+	}
+	return c
+}
+
+// transaction runs fn against a copy of the data, which replaces the
+// store's data only if fn succeeds
+func (m memoryStore) transaction(fn func(store) error) error {
+	if m.inTx {
+		return fn(m)
+	}
+
+	tx := memoryStore{data: m.data.clone(), inTx: true}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	*m.data = *tx.data
+	return nil
+}
+
+// nextID returns the ID following the largest key of ids
+func nextID[V any](ids map[int]V) int {
+	next := 1
+	for id := range ids {
+		if id >= next {
+			next = id + 1
+		}
+	}
+	return next
+}
+
+func (m memoryStore) season(id int) (Season, bool) {
+	ms, found := m.data.seasons[id]
+	if !found {
+		return Season{}, false
+	}
+	return Season{ms.id, m.data.leagues[ms.league], ms.year, ms.seasonType, ms.exhibition}, true
+}
+
+// seasonClub returns the iteration of the club playing in season
+func (m memoryStore) seasonClub(season, club int) (Club, bool) {
+	iteration, found := m.data.seasonClubs[season][club]
+	if !found {
+		return Club{}, false
+	}
+	return m.data.clubs[clubKey{club, iteration}], true
+}
+
+func (m memoryStore) toGame(mg memoryGame) Game {
+	season, _ := m.season(mg.season)
+	home, _ := m.seasonClub(mg.season, mg.home)
+	away, _ := m.seasonClub(mg.season, mg.away)
+	return Game{
+		ID:        mg.id,
+		Season:    season,
+		Date:      mg.date,
+		Time:      mg.time,
+		Status:    mg.status,
+		Title:     mg.title,
+		Venue:     mg.venue,
+		Home:      home,
+		HomeScore: mg.homeScore,
+		Away:      away,
+		AwayScore: mg.awayScore,
+	}
+}
+
+func (m memoryStore) league(code string) (League, error) {
+	league, found := m.data.leagues[strings.ToUpper(code)]
+	if !found {
+		return league, sql.ErrNoRows
+	}
+	return league, nil
+}
+
+func (m memoryStore) leagues() ([]League, error) {
+	var leagues []League
+	for _, league := range m.data.leagues {
+		leagues = append(leagues, league)
+	}
+	sort.Slice(leagues, func(i, j int) bool {
+		return leagues[i].Code < leagues[j].Code
+	})
+	return leagues, nil
+}
+
+func (m memoryStore) seasons(filter seasonFilter) ([]Season, error) {
+	var seasons []Season
+	league, leagueSet := filter.League()
+	club, clubSet := filter.Club()
+	for id := range m.data.seasons {
+		season, _ := m.season(id)
+		if leagueSet && season.League.Code != league.Code {
+			continue
+		}
+		if clubSet {
+			if _, plays := m.data.seasonClubs[id][club.ID]; !plays {
+				continue
+			}
+		}
+		seasons = append(seasons, season)
+	}
+
+	// Seasons always ordered in chronological order
+	sort.Slice(seasons, func(i, j int) bool {
+		if seasons[i].Year != seasons[j].Year {
+			return seasons[i].Year < seasons[j].Year
+		}
+		return seasons[i].ID > seasons[j].ID
+	})
+	if lim, set := filter.Limit(); set && lim < len(seasons) {
+		seasons = seasons[:lim]
+	}
+	return seasons, nil
+}
+
+func (m memoryStore) activeSeason(league League) (Season, error) {
+	id, found := m.data.activeSeasons[strings.ToUpper(league.Code)]
+	if !found {
+		return Season{}, sql.ErrNoRows
+	}
+	season, _ := m.season(id)
+	return season, nil
+}
+
+func (m memoryStore) clubsByLeague(league League, active bool) ([]Club, error) {
+	code := strings.ToUpper(league.Code)
+	var clubs []Club
+	if active {
+		for id, iteration := range m.data.activeClubs[code] {
+			clubs = append(clubs, m.data.clubs[clubKey{id, iteration}])
+		}
+		sort.Slice(clubs, func(i, j int) bool {
+			if clubs[i].Represents != clubs[j].Represents {
+				return clubs[i].Represents < clubs[j].Represents
+			}
+			return clubs[i].Nickname < clubs[j].Nickname
+		})
+		return clubs, nil
+	}
+
+	// Latest iteration of every club to have played in the league
+	latest := make(map[int]int)
+	for id, season := range m.data.seasons {
+		if season.league != code {
+			continue
+		}
+		for club, iteration := range m.data.seasonClubs[id] {
+			if iteration > latest[club] {
+				latest[club] = iteration
+			}
+		}
+	}
+	for id, iteration := range latest {
+		clubs = append(clubs, m.data.clubs[clubKey{id, iteration}])
+	}
+	sort.Slice(clubs, func(i, j int) bool {
+		return clubs[i].ID < clubs[j].ID
+	})
+	return clubs, nil
+}
+
+func (m memoryStore) clubsBySeason(season Season) ([]Club, error) {
+	var clubs []Club
+	for id := range m.data.seasonClubs[season.ID] {
+		club, _ := m.seasonClub(season.ID, id)
+		clubs = append(clubs, club)
+	}
+	sort.Slice(clubs, func(i, j int) bool {
+		return clubs[i].ID < clubs[j].ID
+	})
+	return clubs, nil
+}
+
+func (m memoryStore) game(id int) (Game, error) {
+	mg, found := m.data.games[id]
+	if !found {
+		return Game{}, sql.ErrNoRows
+	}
+	return m.toGame(mg), nil
+}
+
+// matches reports whether game passes every filter in gf
+func (gf gameFilter) matches(game Game) bool {
+	if len(gf.IDs) > 0 {
+		found := false
+		for _, id := range gf.IDs {
+			found = found || id == game.ID
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(gf.Clubs) > 0 {
+		found := gf.AllClubs
+		for _, club := range gf.Clubs {
+			playing := club.ID == game.Home.ID || club.ID == game.Away.ID
+			if gf.AllClubs {
+				found = found && playing
+			} else {
+				found = found || playing
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(gf.Seasons) > 0 {
+		found := false
+		for _, season := range gf.Seasons {
+			found = found || season.ID == game.Season.ID
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(gf.Leagues) > 0 {
+		found := false
+		for _, league := range gf.Leagues {
+			found = found || strings.EqualFold(league.Code, game.Season.League.Code)
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m memoryStore) games(filter gameFilter) ([]Game, error) {
+	var games []Game
+	for _, mg := range m.data.games {
+		game := m.toGame(mg)
+		if filter.matches(game) {
+			games = append(games, game)
+		}
+	}
+
+	// Games always ordered in reverse chronological order
+	sort.Slice(games, func(i, j int) bool {
+		if games[i].Date != games[j].Date {
+			return games[i].Date > games[j].Date
+		}
+		return games[i].ID > games[j].ID
+	})
+	if lim, set := filter.Limit(); set && lim < len(games) {
+		games = games[:lim]
+	}
+	return games, nil
+}
+
+// checkGame enforces the constraints the SQL schema puts on a game
+func (m memoryStore) checkGame(mg memoryGame) error {
+	if _, found := m.data.seasons[mg.season]; !found {
+		return fmt.Errorf("no season %d", mg.season)
+	}
+	if mg.home == mg.away {
+		return errors.New("home and away clubs must differ")
+	}
+	for _, club := range []int{mg.home, mg.away} {
+		if _, plays := m.data.seasonClubs[mg.season][club]; !plays {
+			return fmt.Errorf("club %d does not play in season %d", club, mg.season)
+		}
+	}
+	if mg.homeScore < 0 || mg.awayScore < 0 {
+		return errors.New("scores cannot be negative")
+	}
+	if err := validDate(mg.date); err != nil {
+		return err
+	}
+	if err := validTime(mg.time); mg.time != "" && err != nil {
+		return err
+	}
+	return validStatus(mg.status)
+}
+
+// clock normalizes a time of day as the SQL stores do
+func clock(time string) string {
+	normalized, _ := timeArg(time).(string)
+	return normalized
+}
+
+func (m memoryStore) createGame(season Season, date string,
+	home Club, homeScore int, away Club, awayScore int,
+	title string, venue string, status string, time string) (Game, error) {
+
+	mg := memoryGame{
+		id:        nextID(m.data.games),
+		season:    season.ID,
+		date:      dateArg(date),
+		time:      clock(time),
+		status:    status,
+		title:     title,
+		venue:     venue,
+		home:      home.ID,
+		homeScore: homeScore,
+		away:      away.ID,
+		awayScore: awayScore,
+	}
+	if s, found := m.data.seasons[season.ID]; !found || s.league != strings.ToUpper(season.League.Code) {
+		return Game{}, fmt.Errorf("no season %d in %s", season.ID, season.League.Code)
+	}
+	if err := m.checkGame(mg); err != nil {
+		return Game{}, err
+	}
+
+	m.data.games[mg.id] = mg
+	return m.toGame(mg), nil
+}
+
+func (m memoryStore) editGame(game Game, edit gameEdit) (Game, error) {
+	mg, found := m.data.games[game.ID]
+	if !found {
+		return game, sql.ErrNoRows
+	}
+
+	assigned, err := edit.assignment(m.toGame(mg))
+	if err != nil {
+		return game, err
+	}
+	mg.season = assigned.Season.ID
+	mg.home, mg.homeScore = assigned.Home.ID, assigned.HomeScore
+	mg.away, mg.awayScore = assigned.Away.ID, assigned.AwayScore
+
+	if date, set := edit.Date(); set {
+		mg.date = dateArg(date)
+	}
+	if time, set := edit.Time(); set {
+		mg.time = clock(time)
+	}
+	if status, set := edit.Status(); set {
+		mg.status = status
+	}
+	if title, set := edit.Title(); set {
+		mg.title = title
+	}
+	if venue, set := edit.Venue(); set {
+		mg.venue = venue
+	}
+	if err := m.checkGame(mg); err != nil {
+		return game, err
+	}
+
+	periods := append([]Period(nil), m.data.periods[mg.id]...)
+	if edit.Swap() {
+		for i := range periods {
+			periods[i].HomeScore, periods[i].AwayScore = periods[i].AwayScore, periods[i].HomeScore
+		}
+	}
+	if p, set := edit.Periods(); set {
+		periods = p
+	}
+	if err := checkPeriods(m.toGame(mg), periods); err != nil {
+		return game, err
+	}
+
+	m.data.games[mg.id] = mg
+	m.data.periods[mg.id] = periods
+
+	// Players of a club replaced in the game leave the box score
+	for key, line := range m.data.statLines {
+		if key.game == mg.id && line.ClubID != mg.home && line.ClubID != mg.away {
+			delete(m.data.statLines, key)
+		}
+	}
+	return m.toGame(mg), nil
+}
+
+func (m memoryStore) deleteGame(game Game) error {
+	if _, found := m.data.games[game.ID]; !found {
+		return sql.ErrNoRows
+	}
+	delete(m.data.games, game.ID)
+	delete(m.data.periods, game.ID)
+	for key := range m.data.statLines {
+		if key.game == game.ID {
+			delete(m.data.statLines, key)
+		}
+	}
+	for id, r := range m.data.resources {
+		if r.game == game.ID {
+			delete(m.data.resources, id)
+		}
+	}
+	return nil
+}
+
+func (m memoryStore) resources(game Game) ([]Resource, error) {
+	var resources []Resource
+	for _, r := range m.data.resources {
+		if r.game == game.ID {
+			resources = append(resources, r.Resource)
+		}
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].ID < resources[j].ID
+	})
+	return resources, nil
+}
+
+func (m memoryStore) resourcesByGame(games []Game) (map[int][]Resource, error) {
+	resources := make(map[int][]Resource, len(games))
+	for _, game := range games {
+		var err error
+		if resources[game.ID], err = m.resources(game); err != nil {
+			return resources, err
+		}
+	}
+	return resources, nil
+}
+
+func (m memoryStore) createResource(game Game, title, url string) (Resource, error) {
+	r := Resource{nextID(m.data.resources), title, url}
+	return r, m.restoreResource(game, r)
+}
+
+func (m memoryStore) deleteResource(resource Resource) error {
+	delete(m.data.resources, resource.ID)
+	return nil
+}
+
+func (m memoryStore) periods(game Game) ([]Period, error) {
+	return append([]Period(nil), m.data.periods[game.ID]...), nil
+}
+
+func (m memoryStore) setPeriods(game Game, periods []Period) error {
+	mg, found := m.data.games[game.ID]
+	if !found {
+		return fmt.Errorf("no game %d", game.ID)
+	}
+	if err := checkPeriods(m.toGame(mg), periods); err != nil {
+		return err
+	}
+	m.data.periods[game.ID] = append([]Period(nil), periods...)
+	return nil
+}
+
+func (m memoryStore) players() ([]Player, error) {
+	var players []Player
+	for _, player := range m.data.players {
+		players = append(players, player)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].ID < players[j].ID
+	})
+	return players, nil
+}
+
+func (m memoryStore) player(id int) (Player, error) {
+	player, found := m.data.players[id]
+	if !found {
+		return player, sql.ErrNoRows
+	}
+	return player, nil
+}
+
+func (m memoryStore) createPlayer(name string) (Player, error) {
+	player := Player{nextID(m.data.players), name}
+	return player, m.restorePlayer(player)
+}
+
+// sortPlayers orders players by name as the SQL stores do
+func sortPlayers(players []Player) {
+	sort.Slice(players, func(i, j int) bool {
+		if players[i].Name != players[j].Name {
+			return players[i].Name < players[j].Name
+		}
+		return players[i].ID < players[j].ID
+	})
+}
+
+func (m memoryStore) roster(season Season, club Club) ([]Player, error) {
+	var players []Player
+	for key := range m.data.rosters {
+		if key.season == season.ID && key.club == club.ID {
+			players = append(players, m.data.players[key.player])
+		}
+	}
+	sortPlayers(players)
+	return players, nil
+}
+
+func (m memoryStore) addRosterPlayer(season Season, club Club, player Player) error {
+	if _, plays := m.data.seasonClubs[season.ID][club.ID]; !plays {
+		return fmt.Errorf("club %d does not play in season %d", club.ID, season.ID)
+	}
+	if _, found := m.data.players[player.ID]; !found {
+		return fmt.Errorf("no player %d", player.ID)
+	}
+	key := rosterKey{season.ID, club.ID, player.ID}
+	if m.data.rosters[key] {
+		return fmt.Errorf("duplicate roster player %d", player.ID)
+	}
+	m.data.rosters[key] = true
+	return nil
+}
+
+func (m memoryStore) removeRosterPlayer(season Season, club Club, player Player) error {
+	key := rosterKey{season.ID, club.ID, player.ID}
+	if !m.data.rosters[key] {
+		return fmt.Errorf("player %d is not on the roster of club %d", player.ID, club.ID)
+	}
+	delete(m.data.rosters, key)
+	return nil
+}
+
+func (m memoryStore) stats(sport Sport) ([]Stat, error) {
+	var stats []Stat
+	for _, stat := range m.data.stats {
+		if stat.sport == sport.ID {
+			stats = append(stats, stat.Stat)
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].ID < stats[j].ID
+	})
+	return stats, nil
+}
+
+func (m memoryStore) createStat(sport Sport, code, name string) (Stat, error) {
+	stat := Stat{nextID(m.data.stats), strings.ToUpper(code), name}
+	return stat, m.restoreStat(sport, stat)
+}
+
+func (m memoryStore) boxScore(game Game) ([]StatLine, error) {
+	var lines []StatLine
+	for key, line := range m.data.statLines {
+		if key.game == game.ID {
+			lines = append(lines, line)
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Player.Name != lines[j].Player.Name {
+			return lines[i].Player.Name < lines[j].Player.Name
+		}
+		return lines[i].Player.ID < lines[j].Player.ID
+	})
+	return lines, nil
+}
+
+func (m memoryStore) playerLines(player Player) ([]StatLine, error) {
+	var lines []StatLine
+	for key, line := range m.data.statLines {
+		if key.player == player.ID {
+			lines = append(lines, line)
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].GameID < lines[j].GameID
+	})
+	return lines, nil
+}
+
+func (m memoryStore) setStatLine(game Game, line StatLine) error {
+	if err := checkStatLine(game, line); err != nil {
+		return err
+	}
+	if _, found := m.data.games[game.ID]; !found {
+		return fmt.Errorf("no game %d", game.ID)
+	}
+	player, found := m.data.players[line.Player.ID]
+	if !found {
+		return fmt.Errorf("no player %d", line.Player.ID)
+	}
+	if !m.data.rosters[rosterKey{game.Season.ID, line.ClubID, player.ID}] {
+		return fmt.Errorf("player %d is not on the %d %s %s roster of club %d",
+			player.ID, game.Season.Year, game.Season.League.Code, game.Season.Type, line.ClubID)
+	}
+
+	league := m.data.leagues[strings.ToUpper(game.Season.League.Code)]
+	values := make(map[int]int, len(line.Values))
+	for id, value := range line.Values {
+		stat, found := m.data.stats[id]
+		if !found || m.data.sports[stat.sport].Name != league.Sport {
+			return fmt.Errorf("no %s stat %d", game.Season.League.Sport, id)
+		}
+		values[id] = value
+	}
+
+	m.data.statLines[statLineKey{game.ID, player.ID}] = StatLine{
+		GameID: game.ID,
+		Player: player,
+		ClubID: line.ClubID,
+		Values: values,
+	}
+	return nil
+}
+
+func (m memoryStore) deleteStatLine(game Game, player Player) error {
+	key := statLineKey{game.ID, player.ID}
+	if _, found := m.data.statLines[key]; !found {
+		return fmt.Errorf("player %d has no stat line in game %d", player.ID, game.ID)
+	}
+	delete(m.data.statLines, key)
+	return nil
+}
+
+func (m memoryStore) sports() ([]Sport, error) {
+	var sports []Sport
+	for _, sport := range m.data.sports {
+		sports = append(sports, sport)
+	}
+	sort.Slice(sports, func(i, j int) bool {
+		return sports[i].ID < sports[j].ID
+	})
+	return sports, nil
+}
+
+func (m memoryStore) clubs() ([]Club, error) {
+	var clubs []Club
+	for _, club := range m.data.clubs {
+		clubs = append(clubs, club)
+	}
+	sort.Slice(clubs, func(i, j int) bool {
+		if clubs[i].ID != clubs[j].ID {
+			return clubs[i].ID < clubs[j].ID
+		}
+		return clubs[i].Iteration < clubs[j].Iteration
+	})
+	return clubs, nil
+}
+
+func (m memoryStore) createSport(name string, periods int, periodName, overtimeName string) (Sport, error) {
+	sport := Sport{nextID(m.data.sports), name, periods, periodName, overtimeName}
+	return sport, m.restoreSport(sport)
+}
+
+func (m memoryStore) editSport(sport Sport) (Sport, error) {
+	s, found := m.data.sports[sport.ID]
+	if !found {
+		return sport, sql.ErrNoRows
+	}
+	if sport.Periods < 0 {
+		return sport, errors.New("periods cannot be negative")
+	}
+	s.Periods, s.PeriodName, s.OvertimeName = sport.Periods, sport.PeriodName, sport.OvertimeName
+	m.data.sports[s.ID] = s
+	return s, nil
+}
+
+func (m memoryStore) createLeague(code, name string, sport Sport) (League, error) {
+	s, found := m.data.sports[sport.ID]
+	if !found {
+		return League{}, fmt.Errorf("no sport %d", sport.ID)
+	}
+	league := League{Sport: s.Name, Code: strings.ToUpper(code), Name: name}
+	return league, m.restoreLeague(league)
+}
+
+func (m memoryStore) createClub(represents, nickname string) (Club, error) {
+	next := 1
+	for key := range m.data.clubs {
+		if key.id >= next {
+			next = key.id + 1
+		}
+	}
+	club := Club{next, 1, represents, nickname}
+	return club, m.restoreClub(club)
+}
+
+func (m memoryStore) createClubIteration(club Club, represents, nickname string) (Club, error) {
+	next := 0
+	for key := range m.data.clubs {
+		if key.id == club.ID && key.iteration >= next {
+			next = key.iteration + 1
+		}
+	}
+	if next == 0 {
+		return Club{}, fmt.Errorf("no club %d", club.ID)
+	}
+	iteration := Club{club.ID, next, represents, nickname}
+	return iteration, m.restoreClub(iteration)
+}
+
+func (m memoryStore) setActiveClub(league League, club Club) error {
+	if _, found := m.data.clubs[clubKey{club.ID, club.Iteration}]; !found {
+		return fmt.Errorf("no club %d/%d", club.ID, club.Iteration)
+	}
+	delete(m.data.activeClubs[strings.ToUpper(league.Code)], club.ID)
+	return m.restoreActiveClub(league, club)
+}
+
+func (m memoryStore) removeActiveClub(league League, club Club) error {
+	code := strings.ToUpper(league.Code)
+	if _, found := m.data.activeClubs[code][club.ID]; !found {
+		return fmt.Errorf("club %d is not active in %s", club.ID, code)
+	}
+	delete(m.data.activeClubs[code], club.ID)
+	return nil
+}
+
+func (m memoryStore) createSeason(league League, year int, seasonType string, exhibition, active bool) (Season, error) {
+	season := Season{nextID(m.data.seasons), league, year, seasonType, exhibition}
+	if err := m.restoreSeason(season, active); err != nil {
+		return Season{}, err
+	}
+	for id, iteration := range m.data.activeClubs[strings.ToUpper(league.Code)] {
+		if err := m.restoreSeasonClub(season, Club{ID: id, Iteration: iteration}); err != nil {
+			return Season{}, err
+		}
+	}
+	return season, nil
+}
+
+func (m memoryStore) empty() (bool, error) {
+	d := m.data
+	return len(d.sports) == 0 && len(d.leagues) == 0 &&
+		len(d.clubs) == 0 && len(d.games) == 0 && len(d.players) == 0, nil
+}
+
+func (m memoryStore) restoreSport(sport Sport) error {
+	if _, found := m.data.sports[sport.ID]; found {
+		return fmt.Errorf("duplicate sport %d", sport.ID)
+	}
+	for _, s := range m.data.sports {
+		if s.Name == sport.Name {
+			return fmt.Errorf("duplicate sport %s", sport.Name)
+		}
+	}
+	if sport.Periods < 0 {
+		return errors.New("periods cannot be negative")
+	}
+	m.data.sports[sport.ID] = sport
+	return nil
+}
+
+func (m memoryStore) restoreLeague(league League) error {
+	league.Code = strings.ToUpper(league.Code)
+	if _, found := m.data.leagues[league.Code]; found {
+		return fmt.Errorf("duplicate league %s", league.Code)
+	}
+	for _, sport := range m.data.sports {
+		if sport.Name == league.Sport {
+			m.data.leagues[league.Code] = league
+			return nil
+		}
+	}
+	return fmt.Errorf("league %s: no sport %s", league.Code, league.Sport)
+}
+
+func (m memoryStore) restoreClub(club Club) error {
+	key := clubKey{club.ID, club.Iteration}
+	if _, found := m.data.clubs[key]; found {
+		return fmt.Errorf("duplicate club %d/%d", club.ID, club.Iteration)
+	}
+	m.data.clubs[key] = club
+	return nil
+}
+
+func (m memoryStore) restoreActiveClub(league League, club Club) error {
+	code := strings.ToUpper(league.Code)
+	if _, found := m.data.leagues[code]; !found {
+		return fmt.Errorf("no league %s", code)
+	}
+	if _, found := m.data.clubs[clubKey{club.ID, club.Iteration}]; !found {
+		return fmt.Errorf("no club %d/%d", club.ID, club.Iteration)
+	}
+	if _, found := m.data.activeClubs[code][club.ID]; found {
+		return fmt.Errorf("duplicate active club %d in %s", club.ID, code)
+	}
+	if m.data.activeClubs[code] == nil {
+		m.data.activeClubs[code] = make(map[int]int)
+	}
+	m.data.activeClubs[code][club.ID] = club.Iteration
+	return nil
+}
+
+func (m memoryStore) restoreSeason(season Season, active bool) error {
+	code := strings.ToUpper(season.League.Code)
+	if _, found := m.data.leagues[code]; !found {
+		return fmt.Errorf("no league %s", code)
+	}
+	if _, found := m.data.seasons[season.ID]; found {
+		return fmt.Errorf("duplicate season %d", season.ID)
+	}
+	for _, s := range m.data.seasons {
+		if s.league == code && s.year == season.Year && s.seasonType == season.Type {
+			return fmt.Errorf("duplicate %d %s season in %s", season.Year, season.Type, code)
+		}
+	}
+
+	m.data.seasons[season.ID] = memorySeason{season.ID, code, season.Year, season.Type, season.Exhibition}
+	if active {
+		m.data.activeSeasons[code] = season.ID
+	}
+	return nil
+}
+
+func (m memoryStore) restoreSeasonClub(season Season, club Club) error {
+	if _, found := m.data.seasons[season.ID]; !found {
+		return fmt.Errorf("no season %d", season.ID)
+	}
+	if _, found := m.data.clubs[clubKey{club.ID, club.Iteration}]; !found {
+		return fmt.Errorf("no club %d/%d", club.ID, club.Iteration)
+	}
+	if _, found := m.data.seasonClubs[season.ID][club.ID]; found {
+		return fmt.Errorf("duplicate club %d in season %d", club.ID, season.ID)
+	}
+	if m.data.seasonClubs[season.ID] == nil {
+		m.data.seasonClubs[season.ID] = make(map[int]int)
+	}
+	m.data.seasonClubs[season.ID][club.ID] = club.Iteration
+	return nil
+}
+
+func (m memoryStore) restoreGame(game Game) error {
+	if _, found := m.data.games[game.ID]; found {
+		return fmt.Errorf("duplicate game %d", game.ID)
+	}
+	mg := memoryGame{
+		id:        game.ID,
+		season:    game.Season.ID,
+		date:      dateArg(game.Date),
+		time:      clock(game.Time),
+		status:    game.Status,
+		title:     game.Title,
+		venue:     game.Venue,
+		home:      game.Home.ID,
+		homeScore: game.HomeScore,
+		away:      game.Away.ID,
+		awayScore: game.AwayScore,
+	}
+	if err := m.checkGame(mg); err != nil {
+		return err
+	}
+	m.data.games[mg.id] = mg
+	return nil
+}
+
+func (m memoryStore) restoreResource(game Game, resource Resource) error {
+	if _, found := m.data.games[game.ID]; !found {
+		return fmt.Errorf("no game %d", game.ID)
+	}
+	if _, found := m.data.resources[resource.ID]; found {
+		return fmt.Errorf("duplicate resource %d", resource.ID)
+	}
+	m.data.resources[resource.ID] = memoryResource{resource, game.ID}
+	return nil
+}
+
+func (m memoryStore) restorePlayer(player Player) error {
+	if _, found := m.data.players[player.ID]; found {
+		return fmt.Errorf("duplicate player %d", player.ID)
+	}
+	m.data.players[player.ID] = player
+	return nil
+}
+
+func (m memoryStore) restoreStat(sport Sport, stat Stat) error {
+	if _, found := m.data.sports[sport.ID]; !found {
+		return fmt.Errorf("no sport %d", sport.ID)
+	}
+	if _, found := m.data.stats[stat.ID]; found {
+		return fmt.Errorf("duplicate stat %d", stat.ID)
+	}
+	for _, s := range m.data.stats {
+		if s.sport == sport.ID && s.Code == stat.Code {
+			return fmt.Errorf("duplicate %s stat %s", sport.Name, stat.Code)
+		}
+	}
+	m.data.stats[stat.ID] = memoryStat{stat, sport.ID}
+	return nil
+}
+
+// resetIdentities is a no-op: new IDs always follow the largest
+// ID in use
+func (m memoryStore) resetIdentities() error {
+	return nil
+}
@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migration is one versioned change to the schema, read from a file
+// named NNNN_description.sql
+type migration struct {
+	version int
+	name    string
+	script  string
+}
+
+// migrator is implemented by stores whose schema is managed by
+// migrations
+type migrator interface {
+	// schemaVersion returns the version of the last migration applied,
+	// or 0 if none have been
+	schemaVersion() (int, error)
+
+	// applyMigration runs m and records its version in one transaction
+	applyMigration(m migration) error
+}
+
+// migrationDirs names the directory of each driver's migrations
+var migrationDirs = map[string]string{
+	"postgres": "postgres",
+	"sqlite3":  "sqlite",
+}
+
+// loadMigrations reads the migrations in dir of fsys, ordered by version
+func loadMigrations(fsys fs.FS, dir string) ([]migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []migration
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || path.Ext(name) != ".sql" {
+			continue
+		}
+
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("migration %s: name must begin with a version number", name)
+		}
+
+		script, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations,
+			migration{version, strings.TrimSuffix(name, ".sql"), string(script)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	for i, m := range migrations {
+		if m.version != i+1 {
+			return nil, fmt.Errorf("migration %s: expected version %d", m.name, i+1)
+		}
+	}
+
+	return migrations, nil
+}
+
+// latestVersion is the schema version the code expects
+func latestVersion(migrations []migration) int {
+	return len(migrations)
+}
+
+func (store sqlStore) schemaVersionFrom(exists string) (int, error) {
+	var found bool
+	if err := store.QueryRow(exists).Scan(&found); err != nil || !found {
+		return 0, err
+	}
+
+	var version int
+	q := "SELECT coalesce(max(version), 0) FROM schema_migration"
+	err := store.QueryRow(q).Scan(&version)
+	return version, err
+}
+
+func (store sqlStore) applyMigration(m migration) error {
+	return store.transact(func(tx sqlStore) error {
+		q :=
+			`
+			CREATE TABLE IF NOT EXISTS schema_migration (
+				version int primary key,
+				name varchar(128) not null,
+				applied timestamp not null default current_timestamp
+			)
+			`
+		if _, err := tx.Exec(q); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(m.script); err != nil {
+			return fmt.Errorf("migration %s: %w", m.name, err)
+		}
+
+		q = "INSERT INTO schema_migration(version, name) VALUES($1, $2)"
+		_, err := tx.Exec(q, m.version, m.name)
+		return err
+	})
+}
+
+// checkSchema returns an error unless the schema of db is at the
+// version the code expects
+func checkSchema(db migrator, migrations []migration) error {
+	version, err := db.schemaVersion()
+	if err != nil {
+		return err
+	}
+
+	latest := latestVersion(migrations)
+	switch {
+	case version < latest:
+		return fmt.Errorf("database schema is at version %d but recap expects %d, run \"recap migrate\"",
+			version, latest)
+	case version > latest:
+		return fmt.Errorf("database schema is at version %d, newer than the %d this recap supports",
+			version, latest)
+	}
+	return nil
+}
+
+// migrate applies every migration newer than the schema of db
+func migrate(db migrator, migrations []migration) ([]migration, error) {
+	version, err := db.schemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []migration
+	for _, m := range migrations {
+		if m.version <= version {
+			continue
+		}
+		if err := db.applyMigration(m); err != nil {
+			return applied, err
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
@@ -0,0 +1,20 @@
+package internal
+
+import (
+	"github.com/tdewolff/minify"
+	"github.com/tdewolff/minify/css"
+	"github.com/tdewolff/minify/html"
+)
+
+// newMinifier returns a minifier for templates and stylesheets, set up
+// as the minifier command that make runs sets it up
+func newMinifier() *minify.M {
+	m := minify.New()
+	m.Add("text/html", &html.Minifier{
+		KeepDocumentTags: true,
+		KeepEndTags:      true,
+		KeepWhitespace:   false,
+	})
+	m.AddFunc("text/css", css.Minify)
+	return m
+}
@@ -1,8 +1,20 @@
 package internal
 
+import (
+	"fmt"
+	"strings"
+)
+
+// Sport defines how games of the sport divide into periods for line
+// scores. Periods is the number of periods in regulation, or 0 if
+// games of the sport have no line score. Periods past regulation are
+// named with OvertimeName, or numbered on if it is empty, as innings are.
 type Sport struct {
-	ID   int
-	Name string
+	ID           int
+	Name         string
+	Periods      int
+	PeriodName   string
+	OvertimeName string
 }
 
 type League struct {
@@ -30,6 +42,8 @@ type Game struct {
 	ID        int
 	Season    Season
 	Date      string
+	Time      string
+	Status    string
 	Title     string
 	Venue     string
 	Home      Club
@@ -38,8 +52,78 @@ type Game struct {
 	AwayScore int
 }
 
+// Game statuses. A game is entered as scheduled and becomes final,
+// or a forfeit, once it has a result.
+const (
+	statusScheduled = "scheduled"
+	statusFinal     = "final"
+	statusPostponed = "postponed"
+	statusCancelled = "cancelled"
+	statusForfeit   = "forfeit"
+)
+
+var gameStatuses = []string{statusScheduled, statusFinal, statusPostponed, statusCancelled, statusForfeit}
+
+// Decided reports whether the game has a score to show, which a
+// forfeit has along with a final game
+func (g Game) Decided() bool {
+	return g.Status == statusFinal || g.Status == statusForfeit
+}
+
+// Final reports whether the game was played to a result, the only
+// games that count in records, standings and series
+func (g Game) Final() bool {
+	return g.Status == statusFinal
+}
+
+// Upcoming reports whether the game is yet to be played
+func (g Game) Upcoming() bool {
+	return g.Status == statusScheduled
+}
+
+// StatusLine describes a game without a result, such as "Scheduled
+// 19:30" or "Postponed"
+func (g Game) StatusLine() string {
+	if g.Status == "" {
+		return ""
+	}
+	line := strings.ToUpper(g.Status[:1]) + g.Status[1:]
+	if g.Upcoming() && g.Time != "" {
+		line = fmt.Sprintf("%s %s", line, g.Time)
+	}
+	return line
+}
+
 type Resource struct {
 	ID    int
 	Title string
 	URL   string
 }
+
+// Period is the scoring of one period of a game's line score
+type Period struct {
+	Number    int
+	HomeScore int
+	AwayScore int
+}
+
+type Player struct {
+	ID   int
+	Name string
+}
+
+// Stat is a column of the box scores of a sport, e.g. PTS for points
+type Stat struct {
+	ID   int
+	Code string
+	Name string
+}
+
+// StatLine is a player's statistics in a game for the club with
+// ClubID, keyed by stat ID
+type StatLine struct {
+	GameID int
+	Player Player
+	ClubID int
+	Values map[int]int
+}
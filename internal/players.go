@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+func playerPath(player Player) string {
+	return fmt.Sprintf("/players/%d.html", player.ID)
+}
+
+// checkStatLine verifies that line is for a club playing in game and
+// that its values are not negative
+func checkStatLine(game Game, line StatLine) error {
+	if line.ClubID != game.Home.ID && line.ClubID != game.Away.ID {
+		return fmt.Errorf("club %d does not play in game %d", line.ClubID, game.ID)
+	}
+	for _, value := range line.Values {
+		if value < 0 {
+			return errors.New("stats cannot be negative")
+		}
+	}
+	return nil
+}
+
+// statIDs returns the IDs of the stats in line in order
+func statIDs(line StatLine) []int {
+	ids := make([]int, 0, len(line.Values))
+	for id := range line.Values {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// statValues lists the values of line in the order of stats, with
+// "-" for a stat the line lacks
+func statValues(stats []Stat, line StatLine) []string {
+	values := make([]string, len(stats))
+	for i, stat := range stats {
+		if value, found := line.Values[stat.ID]; found {
+			values[i] = fmt.Sprint(value)
+		} else {
+			values[i] = "-"
+		}
+	}
+	return values
+}
+
+// boxScoreLine is one player's row of a box score
+type boxScoreLine struct {
+	Player Player
+	Values []string
+}
+
+// boxScore is the table of one club's stat lines in a game
+type boxScore struct {
+	Club  Club
+	Stats []Stat
+	Lines []boxScoreLine
+}
+
+// newBoxScores splits the stat lines of game into a box score for
+// the home club and one for the away club, leaving out a club
+// without any lines
+func newBoxScores(game Game, stats []Stat, lines []StatLine) []boxScore {
+	var scores []boxScore
+	for _, club := range []Club{game.Home, game.Away} {
+		score := boxScore{Club: club, Stats: stats}
+		for _, line := range lines {
+			if line.ClubID == club.ID {
+				score.Lines = append(score.Lines, boxScoreLine{line.Player, statValues(stats, line)})
+			}
+		}
+		if len(score.Lines) > 0 {
+			scores = append(scores, score)
+		}
+	}
+	return scores
+}
+
+// playerGame is one game of a player's page, seen from their club
+type playerGame struct {
+	Game     Game
+	Club     Club
+	Opponent Club
+	Home     bool
+	Values   []string
+}
+
+// Result describes the game's outcome for the player's club, e.g.
+// "W 101-99", or its status if it was not played to a result
+func (pg playerGame) Result() string {
+	if !pg.Game.Final() {
+		return pg.Game.StatusLine()
+	}
+	score, opponentScore := pg.Game.HomeScore, pg.Game.AwayScore
+	if !pg.Home {
+		score, opponentScore = opponentScore, score
+	}
+	switch {
+	case score > opponentScore:
+		return fmt.Sprintf("W %d-%d", score, opponentScore)
+	case score < opponentScore:
+		return fmt.Sprintf("L %d-%d", score, opponentScore)
+	default:
+		return fmt.Sprintf("T %d-%d", score, opponentScore)
+	}
+}
+
+// playerSeason is the table of a player's games in one season
+type playerSeason struct {
+	Season Season
+	Stats  []Stat
+	Games  []playerGame
+}
+
+// newPlayerSeasons groups the games of a player's stat lines by
+// season, newest season first and oldest game first within a season.
+// games are ordered newest first as returned by store.games, and
+// stats gives the box score columns of each sport by name.
+func newPlayerSeasons(games []Game, lines []StatLine, stats map[string][]Stat) []playerSeason {
+	byGame := make(map[int]StatLine, len(lines))
+	for _, line := range lines {
+		byGame[line.GameID] = line
+	}
+
+	var seasons []playerSeason
+	bySeason := make(map[int]int)
+	for i := len(games) - 1; i >= 0; i-- {
+		game := games[i]
+		line, found := byGame[game.ID]
+		if !found {
+			continue
+		}
+
+		j, found := bySeason[game.Season.ID]
+		if !found {
+			j = len(seasons)
+			bySeason[game.Season.ID] = j
+			seasons = append(seasons, playerSeason{
+				Season: game.Season,
+				Stats:  stats[game.Season.League.Sport],
+			})
+		}
+
+		pg := playerGame{Game: game, Home: line.ClubID == game.Home.ID}
+		pg.Club, pg.Opponent = game.Away, game.Home
+		if pg.Home {
+			pg.Club, pg.Opponent = game.Home, game.Away
+		}
+		pg.Values = statValues(seasons[j].Stats, line)
+		seasons[j].Games = append(seasons[j].Games, pg)
+	}
+
+	// Seasons were found oldest first
+	for i, j := 0, len(seasons)-1; i < j; i, j = i+1, j-1 {
+		seasons[i], seasons[j] = seasons[j], seasons[i]
+	}
+	return seasons
+}
@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestPlayerGameResult(t *testing.T) {
+	tests := []struct {
+		status    string
+		home      bool
+		homeScore int
+		awayScore int
+		time      string
+		want      string
+	}{
+		{statusFinal, true, 101, 99, "", "W 101-99"},
+		{statusFinal, false, 101, 99, "", "L 99-101"},
+		{statusFinal, false, 88, 88, "", "T 88-88"},
+		{statusForfeit, true, 2, 0, "", "Forfeit"},
+		{statusScheduled, true, 0, 0, "19:30", "Scheduled 19:30"},
+		{statusPostponed, false, 0, 0, "19:30", "Postponed"},
+		{statusCancelled, true, 0, 0, "", "Cancelled"},
+	}
+
+	for _, test := range tests {
+		pg := playerGame{
+			Game: Game{Status: test.status, Time: test.time, HomeScore: test.homeScore, AwayScore: test.awayScore},
+			Home: test.home,
+		}
+		if got := pg.Result(); got != test.want {
+			t.Errorf("%s %d-%d: got %q, want %q", test.status, test.homeScore, test.awayScore, got, test.want)
+		}
+	}
+}
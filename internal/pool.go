@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"runtime"
+	"sync"
+)
+
+// renderPool writes pages on a fixed number of goroutines. Jobs must
+// only read from the store, which is safe for concurrent use as long
+// as nothing writes to it.
+type renderPool struct {
+	jobs chan func() error
+	wg   sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
+}
+
+// newRenderPool starts workers goroutines, or one per CPU if workers
+// is not positive
+func newRenderPool(workers int) *renderPool {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	pool := &renderPool{jobs: make(chan func() error, workers)}
+	pool.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go pool.work()
+	}
+	return pool
+}
+
+func (pool *renderPool) work() {
+	defer pool.wg.Done()
+	for job := range pool.jobs {
+		if pool.failed() {
+			continue
+		}
+		if err := job(); err != nil {
+			pool.mu.Lock()
+			if pool.err == nil {
+				pool.err = err
+			}
+			pool.mu.Unlock()
+		}
+	}
+}
+
+func (pool *renderPool) failed() bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	return pool.err != nil
+}
+
+// submit queues job, blocking while every worker is busy
+func (pool *renderPool) submit(job func() error) {
+	pool.jobs <- job
+}
+
+// wait stops the pool once every queued job has run, returning the
+// first error any of them returned. Jobs queued after a failure are
+// skipped.
+func (pool *renderPool) wait() error {
+	close(pool.jobs)
+	pool.wg.Wait()
+	return pool.err
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRenderPoolErrors(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+	tests := []struct {
+		name    string
+		results []error
+		want    error
+		ran     int32
+	}{
+		{"no jobs", nil, nil, 0},
+		{"every job succeeds", []error{nil, nil, nil}, nil, 3},
+		{"first error returned", []error{nil, errA, errB}, errA, 2},
+		{"later jobs skipped", []error{errA, nil, nil, nil}, errA, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// One worker runs the jobs in the order they are queued
+			pool := newRenderPool(1)
+			var ran int32
+			for _, result := range test.results {
+				result := result
+				pool.submit(func() error {
+					atomic.AddInt32(&ran, 1)
+					return result
+				})
+			}
+			if err := pool.wait(); err != test.want {
+				t.Errorf("got error %v, want %v", err, test.want)
+			}
+			if ran != test.ran {
+				t.Errorf("ran %d jobs, want %d", ran, test.ran)
+			}
+		})
+	}
+}
+
+func TestRenderPoolWorkers(t *testing.T) {
+	const workers, jobs = 3, 30
+	pool := newRenderPool(workers)
+
+	var mu sync.Mutex
+	running, most, done := 0, 0, 0
+	for i := 0; i < jobs; i++ {
+		pool.submit(func() error {
+			mu.Lock()
+			running++
+			if running > most {
+				most = running
+			}
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			running--
+			done++
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := pool.wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	if done != jobs {
+		t.Errorf("ran %d jobs, want %d", done, jobs)
+	}
+	if most > workers {
+		t.Errorf("ran %d jobs at once on %d workers", most, workers)
+	}
+}
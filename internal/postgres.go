@@ -0,0 +1,70 @@
+package internal
+
+import "fmt"
+
+// postgresStore is the store backed by PostgreSQL and the schema
+// built by sql/migrations/postgres
+type postgresStore struct {
+	sqlStore
+}
+
+func (store postgresStore) transaction(fn func(store) error) error {
+	return store.transact(func(tx sqlStore) error {
+		return fn(postgresStore{tx})
+	})
+}
+
+func (store postgresStore) createGame(season Season, date string,
+	home Club, homeScore int, away Club, awayScore int,
+	title string, venue string, status string, time string) (Game, error) {
+
+	var id int
+	q := "SELECT new_game(upper($1), $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)"
+	err := store.
+		QueryRow(q,
+			season.League.Code,
+			season.ID,
+			home.ID,
+			homeScore,
+			away.ID,
+			awayScore,
+			date,
+			title,
+			venue,
+			status,
+			timeArg(time)).
+		Scan(&id)
+
+	if err != nil {
+		return Game{}, err
+	}
+
+	return store.game(id)
+}
+
+// resetIdentities moves each generated ID past the largest ID
+// in its table
+func (store postgresStore) resetIdentities() error {
+	identities := []struct{ table, column string }{
+		{"sport", "sport_id"},
+		{"season", "season_id"},
+		{"club", "club_id"},
+		{"game", "game_id"},
+		{"resource", "resource_id"},
+		{"player", "player_id"},
+		{"stat", "stat_id"},
+	}
+	for _, id := range identities {
+		q := fmt.Sprintf(
+			"SELECT setval(pg_get_serial_sequence('%[1]s', '%[2]s'), coalesce(max(%[2]s), 0) + 1, false) FROM %[1]s",
+			id.table, id.column)
+		if _, err := store.Exec(q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store postgresStore) schemaVersion() (int, error) {
+	return store.schemaVersionFrom("SELECT to_regclass('schema_migration') IS NOT NULL")
+}
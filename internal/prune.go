@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// generatedExtensions are the kinds of file recap writes under the site
+// root. Anything else, such as the stylesheet, is left alone by prune.
+var generatedExtensions = []string{".html", ".atom", ".ics"}
+
+// expectedPages lists every page the current database generates,
+// keyed by path under the site root
+func (cli CLI) expectedPages() (map[string]bool, error) {
+	pages := map[string]bool{
+		indexPath():           true,
+		feedPath(indexPath()): true,
+	}
+
+	games, err := cli.store.games(gameFilter{})
+	if err != nil {
+		return nil, err
+	}
+	for _, game := range games {
+		pages[gamePath(game)] = true
+		a, b := matchupClubs(game)
+		pages[matchupPath(game.Season.League, a, b)] = true
+	}
+
+	players, err := cli.store.players()
+	if err != nil {
+		return nil, err
+	}
+	for _, player := range players {
+		pages[playerPath(player)] = true
+	}
+
+	leagues, err := cli.store.leagues()
+	if err != nil {
+		return nil, err
+	}
+	for _, league := range leagues {
+		pages[leaguePath(league)] = true
+		pages[feedPath(leaguePath(league))] = true
+
+		filter := seasonFilter{}
+		filter.SetLeague(league)
+		seasons, err := cli.store.seasons(filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, season := range seasons {
+			pages[standingsPath(season)] = true
+			pages[seasonCalendarPath(season)] = true
+
+			clubs, err := cli.store.clubsBySeason(season)
+			if err != nil {
+				return nil, err
+			}
+			for _, club := range clubs {
+				pages[clubPath(club, season)] = true
+				pages[feedPath(clubPath(club, season))] = true
+				pages[calendarPath(clubPath(club, season))] = true
+			}
+		}
+	}
+	return pages, nil
+}
+
+// generatedPage returns the page a file under the site root is, or a
+// gzipped copy of, and whether it is a kind of file recap generates
+func generatedPage(sitePath string) (string, bool) {
+	page := strings.TrimSuffix(sitePath, ".gz")
+	for _, ext := range generatedExtensions {
+		if path.Ext(page) == ext {
+			return page, true
+		}
+	}
+	return "", false
+}
+
+// stalePages lists the generated files under siteRoot that are not of
+// a page in expected, by their path under the site root
+func stalePages(siteRoot string, expected map[string]bool) ([]string, error) {
+	var stale []string
+	err := filepath.WalkDir(siteRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(siteRoot, p)
+		if err != nil {
+			return err
+		}
+		sitePath := "/" + filepath.ToSlash(rel)
+		if page, generated := generatedPage(sitePath); generated && !expected[page] {
+			stale = append(stale, sitePath)
+		}
+		return nil
+	})
+	return stale, err
+}
+
+// sitePages lists the pages generated under siteRoot, including those
+// with only a gzipped copy, by their path under the site root
+func sitePages(siteRoot string) ([]string, error) {
+	var pages []string
+	found := make(map[string]bool)
+	err := filepath.WalkDir(siteRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(siteRoot, p)
+		if err != nil {
+			return err
+		}
+		sitePath := "/" + filepath.ToSlash(rel)
+		if page, generated := generatedPage(sitePath); generated && !found[page] {
+			found[page] = true
+			pages = append(pages, page)
+		}
+		return nil
+	})
+	return pages, err
+}
+
+// staleSidebars lists the sidebar templates under sidebarRoot, which
+// earlier versions of recap wrote for index pages to include
+func staleSidebars(sidebarRoot string) ([]string, error) {
+	var stale []string
+	err := filepath.WalkDir(sidebarRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if filepath.Ext(p) == ".tmpl" {
+			stale = append(stale, p)
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return stale, err
+}
+
+// removeEmptyDirs removes the directories under root, but not root
+// itself, that are left empty, deepest first
+func removeEmptyDirs(root string) error {
+	var dirs []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && p != root {
+			dirs = append(dirs, p)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+	for _, dir := range dirs {
+		if entries, err := os.ReadDir(dir); err == nil && len(entries) == 0 {
+			if err := os.Remove(dir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// prune removes the generated files under siteRoot that the current
+// database no longer produces, and any sidebar templates left behind,
+// returning how many there were. With dryRun they are only listed.
+func (cli CLI) prune(siteRoot string, dryRun bool) (int, error) {
+	pages, err := cli.expectedPages()
+	if err != nil {
+		return 0, err
+	}
+
+	// Walk the build www points at, since WalkDir does not follow a
+	// symlinked root
+	resolved, err := filepath.EvalSymlinks(siteRoot)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	stale, err := stalePages(resolved, pages)
+	if err != nil {
+		return 0, err
+	}
+	for i, sitePath := range stale {
+		stale[i] = filepath.Join(siteRoot, sitePath)
+	}
+
+	sidebarRoot := filepath.Join(cli.docGen.templatePath, "sidebar")
+	staleTemplates, err := staleSidebars(sidebarRoot)
+	if err != nil {
+		return 0, err
+	}
+	stale = append(stale, staleTemplates...)
+
+	for _, p := range stale {
+		if dryRun {
+			fmt.Printf("Would remove %s\n", p)
+			continue
+		}
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return 0, err
+		}
+		fmt.Printf("Removed %s\n", p)
+
+		if rel, err := filepath.Rel(siteRoot, p); err == nil && !strings.HasPrefix(rel, "..") {
+			cli.docGen.manifest.forget("/" + filepath.ToSlash(rel))
+		}
+	}
+
+	if !dryRun && len(stale) > 0 {
+		if err := removeEmptyDirs(resolved); err != nil {
+			return 0, err
+		}
+		if err := removeEmptyDirs(sidebarRoot); err != nil {
+			return 0, err
+		}
+		// Sidebar templates are no longer written, so their directory
+		// goes too once it is empty
+		if entries, err := os.ReadDir(sidebarRoot); err == nil && len(entries) == 0 {
+			if err := os.Remove(sidebarRoot); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(stale), nil
+}
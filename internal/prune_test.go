@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedPage(t *testing.T) {
+	tests := []struct {
+		sitePath  string
+		page      string
+		generated bool
+	}{
+		{"/index.html", "/index.html", true},
+		{"/index.html.gz", "/index.html", true},
+		{"/nba/index.atom.gz", "/nba/index.atom", true},
+		{"/nba/2022/1.ics", "/nba/2022/1.ics", true},
+		{"/recap.css", "", false},
+		{"/recap.css.gz", "", false},
+		{"/robots.txt", "", false},
+	}
+
+	for _, test := range tests {
+		page, generated := generatedPage(test.sitePath)
+		if page != test.page || generated != test.generated {
+			t.Errorf("generatedPage(%q) = %q, %v, want %q, %v",
+				test.sitePath, page, generated, test.page, test.generated)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	db := newMemoryStore()
+	a, err := readArchive(strings.NewReader(testArchive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restoreArchive(db, a); err != nil {
+		t.Fatal(err)
+	}
+	cli := CLI{store: db}
+	cli.docGen.staticPath = filepath.Join(dir, "www")
+	cli.docGen.templatePath = filepath.Join(dir, "templates")
+	cli.docGen.manifest = loadManifest(filepath.Join(dir, "manifest.json"), "v1")
+
+	pages, err := cli.expectedPages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	game, err := db.game(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pages[gamePath(game)] {
+		t.Fatalf("%s not expected", gamePath(game))
+	}
+
+	write := func(path string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var kept []string
+	for page := range pages {
+		path := filepath.Join(cli.docGen.staticPath, page)
+		kept = append(kept, path, path+".gz")
+	}
+	kept = append(kept, filepath.Join(cli.docGen.staticPath, "recap.css"))
+	stale := []string{
+		filepath.Join(cli.docGen.staticPath, "nba", "2019", "1.html"),
+		filepath.Join(cli.docGen.staticPath, "nba", "2019", "1.html.gz"),
+		filepath.Join(cli.docGen.staticPath, "games", "999.html"),
+		filepath.Join(cli.docGen.templatePath, "sidebar", "index.tmpl"),
+		filepath.Join(cli.docGen.templatePath, "sidebar", "NBA", "1.tmpl"),
+	}
+	for _, path := range append(kept, stale...) {
+		write(path)
+	}
+
+	removed, err := cli.prune(cli.docGen.staticPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != len(stale) {
+		t.Errorf("removed %d files, want %d", removed, len(stale))
+	}
+	for _, path := range kept {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+	}
+	for _, path := range stale {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s not removed", path)
+		}
+	}
+	for _, dir := range []string{
+		filepath.Join(cli.docGen.staticPath, "nba", "2019"),
+		filepath.Join(cli.docGen.templatePath, "sidebar"),
+	} {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Errorf("empty directory %s not removed", dir)
+		}
+	}
+}
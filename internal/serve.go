@@ -0,0 +1,226 @@
+package internal
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// contentTypes are the media types of the files under the site root,
+// by extension
+var contentTypes = map[string]string{
+	".html": "text/html; charset=utf-8",
+	".css":  "text/css; charset=utf-8",
+	".atom": "application/atom+xml; charset=utf-8",
+	".ics":  "text/calendar; charset=utf-8",
+	".gz":   "application/gzip",
+}
+
+// refreshInterval is the least time between regenerating the site for
+// page requests
+const refreshInterval = time.Second
+
+// siteServer serves the site for preview, preferring the gzipped copy
+// of a file when the client accepts it. Files are opened through www,
+// so it follows the symlink to whichever build is live. With
+// regenerate, a request for a page first brings the site up to date
+// with the database and templates.
+type siteServer struct {
+	cli        CLI
+	root       http.Dir
+	regenerate bool
+
+	// mu keeps requests from reading pages while they are regenerated
+	mu      sync.RWMutex
+	checked time.Time
+}
+
+func newSiteServer(cli CLI, regenerate bool) *siteServer {
+	return &siteServer{cli: cli, root: http.Dir(cli.docGen.staticPath), regenerate: regenerate}
+}
+
+func (server *siteServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := path.Clean("/" + r.URL.Path)
+	if strings.HasSuffix(r.URL.Path, "/") {
+		name = path.Join(name, "index.html")
+	}
+
+	if server.regenerate && isPage(name) {
+		if err := server.refresh(); err != nil {
+			log.Printf("regenerating: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	server.mu.RLock()
+	defer server.mu.RUnlock()
+
+	if dir, err := server.root.Open(name); err == nil {
+		info, err := dir.Stat()
+		dir.Close()
+		if err == nil && info.IsDir() {
+			http.Redirect(w, r, name+"/", http.StatusMovedPermanently)
+			return
+		}
+	}
+	server.serveFile(w, r, name)
+}
+
+// serveFile serves the file at name or its gzipped copy, decompressing
+// the copy for a client that does not accept gzip if it is all there is
+func (server *siteServer) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	header := w.Header()
+	if contentType, found := contentTypes[path.Ext(name)]; found {
+		header.Set("Content-Type", contentType)
+	}
+	if strings.HasPrefix(name, "/static/") {
+		header.Set("Cache-Control", "public, max-age=3600")
+	} else {
+		header.Set("Cache-Control", "no-cache")
+	}
+
+	gzipped, gzErr := server.root.Open(name + ".gz")
+	if gzErr == nil {
+		defer gzipped.Close()
+		header.Add("Vary", "Accept-Encoding")
+	}
+	if gzErr == nil && acceptsGzip(r) {
+		header.Set("Content-Encoding", "gzip")
+		serveContent(w, r, name, gzipped, "gz")
+		return
+	}
+
+	file, err := server.root.Open(name)
+	if err == nil {
+		defer file.Close()
+		serveContent(w, r, name, file, "")
+		return
+	}
+	if gzErr != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := gunzip(gzipped)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	info, _ := gzipped.Stat()
+	setValidators(header, info, "plain")
+	http.ServeContent(w, r, name, info.ModTime(), bytes.NewReader(body))
+}
+
+// serveContent serves file, with an entity tag telling apart the
+// plain and gzipped variants of name
+func serveContent(w http.ResponseWriter, r *http.Request, name string, file http.File, variant string) {
+	info, err := file.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	setValidators(w.Header(), info, variant)
+	http.ServeContent(w, r, name, info.ModTime(), file)
+}
+
+func setValidators(header http.Header, info fs.FileInfo, variant string) {
+	tag := fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size())
+	if variant != "" {
+		tag = fmt.Sprintf("%s-%s", tag, variant)
+	}
+	header.Set("ETag", fmt.Sprintf("\"%s\"", tag))
+}
+
+func gunzip(r io.Reader) ([]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	return io.ReadAll(gz)
+}
+
+// acceptsGzip reports whether the Accept-Encoding header of r allows a
+// gzip response. An explicit gzip entry takes precedence over "*",
+// whatever order they come in.
+func acceptsGzip(r *http.Request) bool {
+	gzipQ, starQ := -1.0, -1.0
+	for _, header := range r.Header.Values("Accept-Encoding") {
+		for _, coding := range strings.Split(header, ",") {
+			name, params, _ := strings.Cut(coding, ";")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name != "gzip" && name != "*" {
+				continue
+			}
+			q := 1.0
+			if weight, found := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q="); found {
+				var err error
+				if q, err = strconv.ParseFloat(weight, 64); err != nil {
+					q = 0
+				}
+			}
+			if name == "gzip" {
+				gzipQ = q
+			} else {
+				starQ = q
+			}
+		}
+	}
+	if gzipQ >= 0 {
+		return gzipQ > 0
+	}
+	return starQ > 0
+}
+
+// isPage reports whether name is a page recap generates, rather than a
+// static asset or a gzipped copy
+func isPage(name string) bool {
+	_, generated := generatedPage(name)
+	return generated && !strings.HasSuffix(name, ".gz")
+}
+
+// refresh brings the site up to date with the database and templates,
+// at most once every refreshInterval. Only changed pages are written.
+func (server *siteServer) refresh() error {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if time.Since(server.checked) < refreshInterval {
+		return nil
+	}
+	defer func() { server.checked = time.Now() }()
+
+	dg := &server.cli.docGen
+	reloaded, err := dg.reloadTemplates()
+	if err != nil {
+		return err
+	}
+	if reloaded {
+		log.Printf("Reloaded templates")
+	}
+
+	if err = server.cli.writeSite(); err != nil {
+		return err
+	}
+	written, _, removed := dg.manifest.counts()
+	if written+removed == 0 {
+		return nil
+	}
+	log.Printf("Regenerated %d pages and removed %d", written, removed)
+	return dg.saveManifest()
+}
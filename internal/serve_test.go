@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"GZIP", true},
+		{"gzip, deflate, br", true},
+		{"deflate, gzip;q=0.5", true},
+		{"gzip;q=0", false},
+		{"gzip; q=0.0", false},
+		{"*", true},
+		{"*;q=0", false},
+		{"*;q=0, gzip", true},
+		{"gzip;q=0, *", false},
+		{"*, gzip;q=0", false},
+		{"deflate;q=0.5, *;q=0.1", true},
+		{"deflate, br", false},
+		{"identity", false},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if test.header != "" {
+			r.Header.Set("Accept-Encoding", test.header)
+		}
+		if got := acceptsGzip(r); got != test.want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", test.header, got, test.want)
+		}
+	}
+}
+
+func TestServeFile(t *testing.T) {
+	root := t.TempDir()
+	gzipped := func(s string) string {
+		var b bytes.Buffer
+		gz := gzip.NewWriter(&b)
+		gz.Write([]byte(s))
+		gz.Close()
+		return b.String()
+	}
+	files := map[string]string{
+		"index.html":        "plain index",
+		"index.html.gz":     gzipped("gzipped index"),
+		"nba/index.html.gz": gzipped("gzipped only"),
+		"static/recap.css":  "body {}",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server := &siteServer{root: http.Dir(root)}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		encoding string
+		status   int
+		gzipped  bool
+		body     string
+	}{
+		{"gzip accepted", "GET", "/index.html", "gzip", http.StatusOK, true, "gzipped index"},
+		{"gzip not accepted", "GET", "/index.html", "", http.StatusOK, false, "plain index"},
+		{"gzip refused", "GET", "/index.html", "gzip;q=0", http.StatusOK, false, "plain index"},
+		{"directory index", "GET", "/", "gzip", http.StatusOK, true, "gzipped index"},
+		{"only gzipped copy", "GET", "/nba/", "", http.StatusOK, false, "gzipped only"},
+		{"no gzipped copy", "GET", "/static/recap.css", "gzip", http.StatusOK, false, "body {}"},
+		{"directory without slash", "GET", "/nba", "", http.StatusMovedPermanently, false, ""},
+		{"missing", "GET", "/nba/2021/1.html", "gzip", http.StatusNotFound, false, ""},
+		{"method not allowed", "POST", "/index.html", "", http.StatusMethodNotAllowed, false, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(test.method, test.path, nil)
+			if test.encoding != "" {
+				r.Header.Set("Accept-Encoding", test.encoding)
+			}
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, r)
+
+			if w.Code != test.status {
+				t.Fatalf("got status %d, want %d", w.Code, test.status)
+			}
+			if test.status != http.StatusOK {
+				return
+			}
+			if got := w.Header().Get("Content-Encoding") == "gzip"; got != test.gzipped {
+				t.Errorf("got gzipped %v, want %v", got, test.gzipped)
+			}
+			body := w.Body.Bytes()
+			if test.gzipped {
+				gz, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var b bytes.Buffer
+				b.ReadFrom(gz)
+				body = b.Bytes()
+			}
+			if string(body) != test.body {
+				t.Errorf("got body %q, want %q", body, test.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package internal
+
+// sqliteStore is the store backed by SQLite and the schema built by
+// sql/migrations/sqlite. SQLite has no stored procedures, so the
+// inserts Postgres makes in new_game are made here in a transaction.
+type sqliteStore struct {
+	sqlStore
+}
+
+func (store sqliteStore) transaction(fn func(store) error) error {
+	return store.transact(func(tx sqlStore) error {
+		return fn(sqliteStore{tx})
+	})
+}
+
+func (s sqliteStore) createGame(season Season, date string,
+	home Club, homeScore int, away Club, awayScore int,
+	title string, venue string, status string, time string) (Game, error) {
+
+	var id int
+	err := s.transact(func(tx sqlStore) error {
+		league := season.League.Code
+		q :=
+			`
+			INSERT INTO game(league_code, game_date, title, venue, status, game_time)
+			VALUES(upper($1), $2, $3, $4, $5, $6)
+			RETURNING game_id
+			`
+		err := tx.QueryRow(q, league, dateArg(date), title, venue, status, timeArg(time)).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		q =
+			`
+			INSERT INTO game_season(game_id, league_code, season_id)
+			VALUES($1, upper($2), $3)
+			`
+		if _, err = tx.Exec(q, id, league, season.ID); err != nil {
+			return err
+		}
+
+		q =
+			`
+			INSERT INTO game_club(game_id, league_code, season_id, club_id, score)
+			VALUES($1, upper($2), $3, $4, $5), ($1, upper($2), $3, $6, $7)
+			`
+		_, err = tx.Exec(q, id, league, season.ID,
+			home.ID, homeScore, away.ID, awayScore)
+		if err != nil {
+			return err
+		}
+
+		q = "INSERT INTO game_club_home(game_id, club_id) VALUES($1, $2)"
+		_, err = tx.Exec(q, id, home.ID)
+		return err
+	})
+	if err != nil {
+		return Game{}, err
+	}
+
+	return s.game(id)
+}
+
+// resetIdentities is a no-op: SQLite assigns each new integer primary
+// key past the largest one in its table
+func (store sqliteStore) resetIdentities() error {
+	return nil
+}
+
+func (store sqliteStore) schemaVersion() (int, error) {
+	return store.schemaVersionFrom(
+		"SELECT count(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migration'")
+}
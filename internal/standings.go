@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// record counts the results of a set of games
+type record struct {
+	Wins   int
+	Losses int
+	Ties   int
+}
+
+func (r record) played() int {
+	return r.Wins + r.Losses + r.Ties
+}
+
+func (r *record) add(score, opponentScore int) {
+	switch {
+	case score > opponentScore:
+		r.Wins++
+	case score < opponentScore:
+		r.Losses++
+	default:
+		r.Ties++
+	}
+}
+
+func (r record) String() string {
+	return fmt.Sprintf("%d-%d-%d", r.Wins, r.Losses, r.Ties)
+}
+
+// percentage is the share of games won, counting ties as half a win
+func (r record) percentage() float64 {
+	if r.played() == 0 {
+		return 0
+	}
+	return (float64(r.Wins) + float64(r.Ties)/2) / float64(r.played())
+}
+
+// standing is one club's line in a season's standings
+type standing struct {
+	Club Club
+	record
+	PointsFor     int
+	PointsAgainst int
+	Home          record
+	Away          record
+	GamesBack     string
+}
+
+// Percentage formats the win percentage in the usual .000 style
+func (s standing) Percentage() string {
+	return strings.TrimPrefix(fmt.Sprintf("%.3f", s.percentage()), "0")
+}
+
+// Differential formats points for less points against with a sign
+func (s standing) Differential() string {
+	if s.PointsFor == s.PointsAgainst {
+		return "0"
+	}
+	return fmt.Sprintf("%+d", s.PointsFor-s.PointsAgainst)
+}
+
+// standings tallies the final games into a standing for each of
+// clubs, ordered by win percentage, then point differential, then name
+func standings(clubs []Club, games []Game) []standing {
+	lines := make([]standing, len(clubs))
+	byID := make(map[int]*standing, len(clubs))
+	for i, club := range clubs {
+		lines[i].Club = club
+		byID[club.ID] = &lines[i]
+	}
+
+	for _, game := range games {
+		if !game.Final() {
+			continue
+		}
+		if home, found := byID[game.Home.ID]; found {
+			home.record.add(game.HomeScore, game.AwayScore)
+			home.Home.add(game.HomeScore, game.AwayScore)
+			home.PointsFor += game.HomeScore
+			home.PointsAgainst += game.AwayScore
+		}
+		if away, found := byID[game.Away.ID]; found {
+			away.record.add(game.AwayScore, game.HomeScore)
+			away.Away.add(game.AwayScore, game.HomeScore)
+			away.PointsFor += game.AwayScore
+			away.PointsAgainst += game.HomeScore
+		}
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		a, b := lines[i], lines[j]
+		if a.percentage() != b.percentage() {
+			return a.percentage() > b.percentage()
+		}
+		if a.PointsFor-a.PointsAgainst != b.PointsFor-b.PointsAgainst {
+			return a.PointsFor-a.PointsAgainst > b.PointsFor-b.PointsAgainst
+		}
+		return strings.ToLower(clubName(a.Club)) < strings.ToLower(clubName(b.Club))
+	})
+
+	// Games back from the leader, counting a tie as half a game
+	if len(lines) > 0 {
+		leader := lines[0]
+		for i := range lines {
+			back := float64((leader.Wins-lines[i].Wins)+(lines[i].Losses-leader.Losses)) / 2
+			if back <= 0 {
+				lines[i].GamesBack = "-"
+			} else {
+				lines[i].GamesBack = fmt.Sprintf("%.1f", back)
+			}
+		}
+	}
+
+	return lines
+}
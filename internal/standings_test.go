@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// standingsClubs are the clubs the standings tests play with, by
+// abbreviation
+var standingsClubs = map[string]Club{
+	"ATL": {ID: 1, Represents: "Atlanta", Nickname: "Hawks"},
+	"BOS": {ID: 2, Represents: "Boston", Nickname: "Celtics"},
+	"CHI": {ID: 3, Represents: "Chicago", Nickname: "Bulls"},
+	"DAL": {ID: 4, Represents: "Dallas", Nickname: "Mavericks"},
+}
+
+// scoreline parses a game written "HOME score AWAY score [status]",
+// which is final unless another status is given
+func scoreline(t *testing.T, line string) Game {
+	t.Helper()
+	fields := append(strings.Fields(line), statusFinal)
+	if len(fields) < 5 {
+		t.Fatalf("bad scoreline %q", line)
+	}
+	homeScore, err := strconv.Atoi(fields[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	awayScore, err := strconv.Atoi(fields[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Game{
+		Home:      standingsClubs[fields[0]],
+		HomeScore: homeScore,
+		Away:      standingsClubs[fields[2]],
+		AwayScore: awayScore,
+		Status:    fields[4],
+	}
+}
+
+func TestStandings(t *testing.T) {
+	tests := []struct {
+		name  string
+		clubs string
+		games []string
+		want  string // one line per club: record, home, away, pct, diff, GB
+	}{
+		{
+			name:  "no games, by name",
+			clubs: "CHI ATL BOS",
+			want: `
+				Hawks 0-0-0 0-0-0 0-0-0 .000 0 -
+				Celtics 0-0-0 0-0-0 0-0-0 .000 0 -
+				Bulls 0-0-0 0-0-0 0-0-0 .000 0 -`,
+		},
+		{
+			name:  "home and away records",
+			clubs: "ATL BOS CHI",
+			games: []string{"ATL 100 BOS 90", "BOS 101 CHI 99", "CHI 80 ATL 85"},
+			want: `
+				Hawks 2-0-0 1-0-0 1-0-0 1.000 +15 -
+				Celtics 1-1-0 1-0-0 0-1-0 .500 -8 1.0
+				Bulls 0-2-0 0-1-0 0-1-0 .000 -7 2.0`,
+		},
+		{
+			name:  "undecided games skipped",
+			clubs: "ATL BOS CHI",
+			games: []string{
+				"ATL 100 BOS 90",
+				"BOS 0 CHI 0 scheduled",
+				"CHI 0 ATL 0 postponed",
+				"ATL 0 CHI 0 cancelled",
+			},
+			want: `
+				Hawks 1-0-0 1-0-0 0-0-0 1.000 +10 -
+				Bulls 0-0-0 0-0-0 0-0-0 .000 0 0.5
+				Celtics 0-1-0 0-0-0 0-1-0 .000 -10 1.0`,
+		},
+		{
+			name:  "forfeits skipped",
+			clubs: "ATL BOS CHI",
+			games: []string{"ATL 100 BOS 90", "CHI 0 ATL 2 forfeit", "BOS 2 CHI 0 forfeit"},
+			want: `
+				Hawks 1-0-0 1-0-0 0-0-0 1.000 +10 -
+				Bulls 0-0-0 0-0-0 0-0-0 .000 0 0.5
+				Celtics 0-1-0 0-0-0 0-1-0 .000 -10 1.0`,
+		},
+		{
+			name:  "ties count half",
+			clubs: "ATL BOS CHI",
+			games: []string{"ATL 99 BOS 99", "CHI 0 ATL 2"},
+			want: `
+				Hawks 1-0-1 0-0-1 1-0-0 .750 +2 -
+				Celtics 0-0-1 0-0-0 0-0-1 .500 0 0.5
+				Bulls 0-1-0 0-1-0 0-0-0 .000 -2 1.0`,
+		},
+		{
+			name:  "differential breaks ties",
+			clubs: "ATL BOS CHI",
+			games: []string{"ATL 100 CHI 99", "BOS 110 CHI 90"},
+			want: `
+				Celtics 1-0-0 1-0-0 0-0-0 1.000 +20 -
+				Hawks 1-0-0 1-0-0 0-0-0 1.000 +1 -
+				Bulls 0-2-0 0-0-0 0-2-0 .000 -21 1.5`,
+		},
+		{
+			name:  "only listed clubs",
+			clubs: "ATL",
+			games: []string{"DAL 90 ATL 100"},
+			want: `
+				Hawks 1-0-0 0-0-0 1-0-0 1.000 +10 -`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var clubs []Club
+			for _, code := range strings.Fields(test.clubs) {
+				clubs = append(clubs, standingsClubs[code])
+			}
+			var games []Game
+			for _, line := range test.games {
+				games = append(games, scoreline(t, line))
+			}
+
+			var got []string
+			for _, s := range standings(clubs, games) {
+				got = append(got, fmt.Sprintf("%s %s %s %s %s %s %s", s.Club.Nickname,
+					s.record, s.Home, s.Away, s.Percentage(), s.Differential(), s.GamesBack))
+			}
+			var want []string
+			for _, line := range strings.Split(strings.TrimSpace(test.want), "\n") {
+				want = append(want, strings.TrimSpace(line))
+			}
+			if strings.Join(got, "\n") != strings.Join(want, "\n") {
+				t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+			}
+		})
+	}
+}
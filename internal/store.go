@@ -2,12 +2,145 @@ package internal
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
-type store struct {
-	*sql.DB
+// store is the set of operations recap performs on its database.
+// Each backend implements it on top of sqlStore, overriding the
+// operations its SQL dialect handles differently.
+type store interface {
+	// transaction runs fn with a store whose operations all happen in
+	// a single transaction, committed only if fn succeeds. A store
+	// already inside a transaction runs fn in that same transaction.
+	transaction(fn func(store) error) error
+
+	league(code string) (League, error)
+	leagues() ([]League, error)
+	seasons(filter seasonFilter) ([]Season, error)
+	activeSeason(league League) (Season, error)
+	clubsByLeague(league League, active bool) ([]Club, error)
+	clubsBySeason(season Season) ([]Club, error)
+	game(id int) (Game, error)
+	games(filter gameFilter) ([]Game, error)
+	createGame(season Season, date string, home Club, homeScore int,
+		away Club, awayScore int, title string, venue string,
+		status string, time string) (Game, error)
+	editGame(game Game, edit gameEdit) (Game, error)
+	deleteGame(game Game) error
+	resources(game Game) ([]Resource, error)
+	resourcesByGame(games []Game) (map[int][]Resource, error)
+	createResource(game Game, title, url string) (Resource, error)
+	deleteResource(resource Resource) error
+	periods(game Game) ([]Period, error)
+	setPeriods(game Game, periods []Period) error
+
+	players() ([]Player, error)
+	player(id int) (Player, error)
+	createPlayer(name string) (Player, error)
+	roster(season Season, club Club) ([]Player, error)
+	addRosterPlayer(season Season, club Club, player Player) error
+	removeRosterPlayer(season Season, club Club, player Player) error
+	stats(sport Sport) ([]Stat, error)
+	createStat(sport Sport, code, name string) (Stat, error)
+	boxScore(game Game) ([]StatLine, error)
+	playerLines(player Player) ([]StatLine, error)
+	setStatLine(game Game, line StatLine) error
+	deleteStatLine(game Game, player Player) error
+
+	sports() ([]Sport, error)
+	clubs() ([]Club, error)
+	createSport(name string, periods int, periodName, overtimeName string) (Sport, error)
+	editSport(sport Sport) (Sport, error)
+	createLeague(code, name string, sport Sport) (League, error)
+	createClub(represents, nickname string) (Club, error)
+	createClubIteration(club Club, represents, nickname string) (Club, error)
+	setActiveClub(league League, club Club) error
+	removeActiveClub(league League, club Club) error
+	createSeason(league League, year int, seasonType string, exhibition, active bool) (Season, error)
+
+	empty() (bool, error)
+	restoreSport(sport Sport) error
+	restoreLeague(league League) error
+	restoreClub(club Club) error
+	restoreActiveClub(league League, club Club) error
+	restoreSeason(season Season, active bool) error
+	restoreSeasonClub(season Season, club Club) error
+	restoreGame(game Game) error
+	restoreResource(game Game, resource Resource) error
+	restorePlayer(player Player) error
+	restoreStat(sport Sport, stat Stat) error
+	resetIdentities() error
+}
+
+// newStore returns the store for db, opened with the named
+// database/sql driver
+func newStore(driver string, db *sql.DB) (store, error) {
+	switch driver {
+	case "postgres":
+		return postgresStore{sqlStore{db, db}}, nil
+	case "sqlite3":
+		return sqliteStore{sqlStore{db, db}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver \"%s\"", driver)
+	}
+}
+
+// queryer is the subset of *sql.DB and *sql.Tx the store queries through
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// sqlStore implements the operations of store whose SQL is shared
+// by every backend
+type sqlStore struct {
+	queryer
+	db *sql.DB
+}
+
+// transact runs fn with an sqlStore bound to a transaction. Backends
+// wrap it to implement store.transaction.
+func (store sqlStore) transact(fn func(sqlStore) error) error {
+	if _, inTx := store.queryer.(*sql.Tx); inTx {
+		return fn(store)
+	}
+
+	tx, err := store.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(sqlStore{tx, store.db}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// dateArg normalizes a YYYY-M-D date to YYYY-MM-DD so that dates
+// compare and sort the same whether or not the database has a date type
+func dateArg(date string) string {
+	if t, err := time.Parse("2006-1-2", date); err == nil {
+		return t.Format("2006-01-02")
+	}
+	return date
+}
+
+// timeArg normalizes an H:MM time of day to HH:MM, or to NULL if it
+// is empty
+func timeArg(clock string) interface{} {
+	if clock == "" {
+		return nil
+	}
+	if t, err := time.Parse("15:04", clock); err == nil {
+		return t.Format("15:04")
+	}
+	return clock
 }
 
 type filter struct {
@@ -30,6 +163,10 @@ type gameFilter struct {
 	Clubs   []Club
 	Seasons []Season
 	Leagues []League
+
+	// AllClubs requires every one of Clubs to be playing in a game,
+	// rather than any one of them
+	AllClubs bool
 }
 
 type seasonFilter struct {
@@ -60,15 +197,28 @@ func (sf *seasonFilter) SetClub(c Club) {
 
 type gameEdit struct {
 	date         string
+	time         string
+	status       string
 	title        string
 	venue        string
 	homeScore    int
 	awayScore    int
+	periods      []Period
+	season       Season
+	home         Club
+	away         Club
+	swap         bool
 	dateSet      bool
+	timeSet      bool
+	statusSet    bool
 	titleSet     bool
 	venueSet     bool
 	homeScoreSet bool
 	awayScoreSet bool
+	periodsSet   bool
+	seasonSet    bool
+	homeSet      bool
+	awaySet      bool
 }
 
 func (ge *gameEdit) SetDate(date string) {
@@ -76,6 +226,18 @@ func (ge *gameEdit) SetDate(date string) {
 	ge.dateSet = true
 }
 
+// SetTime sets the time of day the game starts, or clears it if time
+// is empty
+func (ge *gameEdit) SetTime(time string) {
+	ge.time = time
+	ge.timeSet = true
+}
+
+func (ge *gameEdit) SetStatus(status string) {
+	ge.status = status
+	ge.statusSet = true
+}
+
 func (ge *gameEdit) SetTitle(title string) {
 	ge.title = title
 	ge.titleSet = true
@@ -96,10 +258,49 @@ func (ge *gameEdit) SetAwayScore(score int) {
 	ge.awayScoreSet = true
 }
 
+// SetPeriods replaces the line score, which must add up to the final
+// score once the edit is applied. Periods follow any swap.
+func (ge *gameEdit) SetPeriods(periods []Period) {
+	ge.periods = periods
+	ge.periodsSet = true
+}
+
+// SetSeason moves the game to another season of the same league
+func (ge *gameEdit) SetSeason(season Season) {
+	ge.season = season
+	ge.seasonSet = true
+}
+
+// SetHome replaces the home club, after any swap
+func (ge *gameEdit) SetHome(club Club) {
+	ge.home = club
+	ge.homeSet = true
+}
+
+// SetAway replaces the away club, after any swap
+func (ge *gameEdit) SetAway(club Club) {
+	ge.away = club
+	ge.awaySet = true
+}
+
+// SwapHomeAway exchanges the home and away clubs along with their
+// scores. Swapping twice restores the original assignment.
+func (ge *gameEdit) SwapHomeAway() {
+	ge.swap = !ge.swap
+}
+
 func (ge gameEdit) Date() (string, bool) {
 	return ge.date, ge.dateSet
 }
 
+func (ge gameEdit) Time() (string, bool) {
+	return ge.time, ge.timeSet
+}
+
+func (ge gameEdit) Status() (string, bool) {
+	return ge.status, ge.statusSet
+}
+
 func (ge gameEdit) Title() (string, bool) {
 	return ge.title, ge.titleSet
 }
@@ -116,7 +317,66 @@ func (ge gameEdit) AwayScore() (int, bool) {
 	return ge.awayScore, ge.awayScoreSet
 }
 
-func (store store) league(code string) (League, error) {
+func (ge gameEdit) Periods() ([]Period, bool) {
+	return ge.periods, ge.periodsSet
+}
+
+func (ge gameEdit) Season() (Season, bool) {
+	return ge.season, ge.seasonSet
+}
+
+func (ge gameEdit) Home() (Club, bool) {
+	return ge.home, ge.homeSet
+}
+
+func (ge gameEdit) Away() (Club, bool) {
+	return ge.away, ge.awaySet
+}
+
+func (ge gameEdit) Swap() bool {
+	return ge.swap
+}
+
+// reassigns reports whether the edit changes the game's season or
+// which club plays home or away
+func (ge gameEdit) reassigns() bool {
+	return ge.seasonSet || ge.homeSet || ge.awaySet || ge.swap
+}
+
+// assignment returns the season, clubs and scores of game once edit
+// is applied. Scores belong to the position, home or away, so a
+// replaced club takes over its predecessor's score.
+func (ge gameEdit) assignment(game Game) (Game, error) {
+	if season, set := ge.Season(); set {
+		if !strings.EqualFold(season.League.Code, game.Season.League.Code) {
+			return game, fmt.Errorf("cannot move a %s game to a %s season",
+				game.Season.League.Code, season.League.Code)
+		}
+		game.Season = season
+	}
+	if ge.Swap() {
+		game.Home, game.Away = game.Away, game.Home
+		game.HomeScore, game.AwayScore = game.AwayScore, game.HomeScore
+	}
+	if club, set := ge.Home(); set {
+		game.Home = club
+	}
+	if club, set := ge.Away(); set {
+		game.Away = club
+	}
+	if score, set := ge.HomeScore(); set {
+		game.HomeScore = score
+	}
+	if score, set := ge.AwayScore(); set {
+		game.AwayScore = score
+	}
+	if game.Home.ID == game.Away.ID {
+		return game, errors.New("home and away clubs must differ")
+	}
+	return game, nil
+}
+
+func (store sqlStore) league(code string) (League, error) {
 	q :=
 		`
 		SELECT league_code, sport_name, league_name
@@ -132,7 +392,7 @@ func (store store) league(code string) (League, error) {
 	return league, err
 }
 
-func (store store) leagues() ([]League, error) {
+func (store sqlStore) leagues() ([]League, error) {
 	q :=
 		`
 		SELECT league_code, sport_name, league_name
@@ -163,7 +423,7 @@ func (store store) leagues() ([]League, error) {
 	return leagues, nil
 }
 
-func (store store) seasons(filter seasonFilter) ([]Season, error) {
+func (store sqlStore) seasons(filter seasonFilter) ([]Season, error) {
 	var seasons []Season
 	q, args := seasonQuery(filter)
 	rows, err := store.Query(q, args...)
@@ -189,7 +449,7 @@ func (store store) seasons(filter seasonFilter) ([]Season, error) {
 	return seasons, nil
 }
 
-func (store store) activeSeason(league League) (Season, error) {
+func (store sqlStore) activeSeason(league League) (Season, error) {
 	var season Season
 	q :=
 		`
@@ -210,7 +470,7 @@ func (store store) activeSeason(league League) (Season, error) {
 	return season, err
 }
 
-func (store store) clubsByLeague(league League, active bool) ([]Club, error) {
+func (store sqlStore) clubsByLeague(league League, active bool) ([]Club, error) {
 	var q string
 	if active {
 		q =
@@ -223,15 +483,16 @@ func (store store) clubsByLeague(league League, active bool) ([]Club, error) {
 	} else {
 		q =
 			`
-			SELECT
-				DISTINCT ON(club_id) "club_id",
-				club_iteration,
-				represents,
-				nickname
+			SELECT DISTINCT club_id, club_iteration, represents, nickname
 			FROM season_club
 			NATURAL JOIN club
 			WHERE league_code = upper($1)
-			ORDER BY club_id, club_iteration desc
+			AND club_iteration = (
+				SELECT max(latest.club_iteration)
+				FROM season_club latest
+				WHERE latest.league_code = season_club.league_code
+				AND latest.club_id = season_club.club_id)
+			ORDER BY club_id
 			`
 	}
 
@@ -258,7 +519,7 @@ func (store store) clubsByLeague(league League, active bool) ([]Club, error) {
 	return clubs, nil
 }
 
-func (store store) clubsBySeason(season Season) ([]Club, error) {
+func (store sqlStore) clubsBySeason(season Season) ([]Club, error) {
 	query :=
 		`
 		SELECT club_id, club_iteration, represents, nickname
@@ -289,18 +550,19 @@ func (store store) clubsBySeason(season Season) ([]Club, error) {
 	return clubs, nil
 }
 
-func (store store) game(id int) (Game, error) {
+func (store sqlStore) game(id int) (Game, error) {
 	q :=
 		`
 		SELECT
 			game_id, season_id, sport_name,
 			league_code, league_name, start_year,
-			season_type, exhibition, game_date::text,
+			season_type, exhibition, CAST(game_date AS text),
 			coalesce(title, ''), coalesce(venue, ''),
 			home_id, home_iteration,
 			home_represents, home_nickname, home_score,
 			away_id, away_iteration, away_represents,
-			away_nickname, away_score
+			away_nickname, away_score, status,
+			coalesce(substr(CAST(game_time AS text), 1, 5), '')
 		FROM game_view
 		WHERE game_id = $1
 		`
@@ -314,12 +576,13 @@ func (store store) game(id int) (Game, error) {
 			&game.Home.Iteration, &game.Home.Represents,
 			&game.Home.Nickname, &game.HomeScore, &game.Away.ID,
 			&game.Away.Iteration, &game.Away.Represents,
-			&game.Away.Nickname, &game.AwayScore)
+			&game.Away.Nickname, &game.AwayScore, &game.Status,
+			&game.Time)
 
 	return game, err
 }
 
-func (store store) games(filter gameFilter) ([]Game, error) {
+func (store sqlStore) games(filter gameFilter) ([]Game, error) {
 	var games []Game
 	q, args := gameQuery(filter)
 	rows, err := store.Query(q, args...)
@@ -337,7 +600,8 @@ func (store store) games(filter gameFilter) ([]Game, error) {
 			&game.Home.Iteration, &game.Home.Represents,
 			&game.Home.Nickname, &game.HomeScore, &game.Away.ID,
 			&game.Away.Iteration, &game.Away.Represents,
-			&game.Away.Nickname, &game.AwayScore)
+			&game.Away.Nickname, &game.AwayScore, &game.Status,
+			&game.Time)
 		if err != nil {
 			return games, err
 		}
@@ -351,47 +615,41 @@ func (store store) games(filter gameFilter) ([]Game, error) {
 	return games, nil
 }
 
-func (store store) createGame(season Season, date string,
-	home Club, homeScore int, away Club, awayScore int,
-	title string, venue string) (Game, error) {
-
-	var id int
-	q := "SELECT new_game(upper($1), $2, $3, $4, $5, $6, $7, $8, $9)"
-	err := store.
-		QueryRow(q,
-			season.League.Code,
-			season.ID,
-			home.ID,
-			homeScore,
-			away.ID,
-			awayScore,
-			date,
-			title,
-			venue).
-		Scan(&id)
-
+func (s sqlStore) editGame(game Game, edit gameEdit) (Game, error) {
+	// Need to make up to three updates - game, home game_club,
+	// and away game_club. Reassigning the season or clubs replaces
+	// the game_club rows instead.
+	assigned, err := edit.assignment(game)
 	if err != nil {
-		return Game{}, err
+		return game, err
 	}
 
-	return store.game(id)
-}
-
-func (store store) editGame(game Game, edit gameEdit) (Game, error) {
-	// Need to make up to three updates - game, home game_club,
-	// and away game_club.
 	// Prepare update to game if necessary
 	updateGame := false
 	var updateGameQuery strings.Builder
 
 	arg := 1
-	gameUpdates := make([]string, 0, 3)
-	gameUpdateArgs := make([]interface{}, 0, 4)
+	gameUpdates := make([]string, 0, 5)
+	gameUpdateArgs := make([]interface{}, 0, 6)
 
 	if date, set := edit.Date(); set {
 		update := fmt.Sprintf("%s = $%d", "game_date", arg)
 		gameUpdates = append(gameUpdates, update)
-		gameUpdateArgs = append(gameUpdateArgs, date)
+		gameUpdateArgs = append(gameUpdateArgs, dateArg(date))
+		arg++
+	}
+
+	if clock, set := edit.Time(); set {
+		update := fmt.Sprintf("%s = $%d", "game_time", arg)
+		gameUpdates = append(gameUpdates, update)
+		gameUpdateArgs = append(gameUpdateArgs, timeArg(clock))
+		arg++
+	}
+
+	if status, set := edit.Status(); set {
+		update := fmt.Sprintf("%s = $%d", "status", arg)
+		gameUpdates = append(gameUpdates, update)
+		gameUpdateArgs = append(gameUpdateArgs, status)
 		arg++
 	}
 
@@ -446,49 +704,131 @@ func (store store) editGame(game Game, edit gameEdit) (Game, error) {
 	}
 
 	// Store the updates
-	tx, err := store.Begin()
+	err = s.transact(func(tx sqlStore) error {
+		if updateGame {
+			_, err := tx.Exec(updateGameQuery.String(), gameUpdateArgs...)
+			if err != nil {
+				return err
+			}
+		}
+
+		if edit.reassigns() {
+			if err := tx.reassignGame(game, assigned); err != nil {
+				return err
+			}
+		} else {
+			if updateClubHome {
+				_, err := tx.Exec(updateClubHomeQuery.String(), updateClubHomeArgs...)
+				if err != nil {
+					return err
+				}
+			}
+
+			if updateClubAway {
+				_, err := tx.Exec(updateClubAwayQuery.String(), updateClubAwayArgs...)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// Periods belong to the position like scores, so swap
+		// with them
+		if edit.Swap() {
+			q := "UPDATE game_period SET home_score = away_score, away_score = home_score WHERE game_id = $1"
+			if _, err := tx.Exec(q, game.ID); err != nil {
+				return err
+			}
+		}
+
+		if periods, set := edit.Periods(); set {
+			return tx.setPeriods(game, periods)
+		}
+
+		// The line score must still add up to the edited score
+		edited, err := tx.game(game.ID)
+		if err != nil {
+			return err
+		}
+		periods, err := tx.periods(edited)
+		if err != nil {
+			return err
+		}
+		return checkPeriods(edited, periods)
+	})
 	if err != nil {
 		return game, err
 	}
 
-	if updateGame {
-		_, err := tx.Exec(updateGameQuery.String(), gameUpdateArgs...)
-		if err != nil {
-			tx.Rollback()
-			return game, err
-		}
+	return s.game(game.ID)
+}
+
+// reassignGame replaces the game_club rows of game with those of
+// assigned, moving the game to assigned's season if it differs
+func (store sqlStore) reassignGame(game Game, assigned Game) error {
+	league := game.Season.League.Code
+	q := "DELETE FROM game_club WHERE game_id = $1"
+	if _, err := store.Exec(q, game.ID); err != nil {
+		return err
 	}
 
-	if updateClubHome {
-		_, err := tx.Exec(updateClubHomeQuery.String(), updateClubHomeArgs...)
-		if err != nil {
-			tx.Rollback()
-			return game, err
+	if assigned.Season.ID != game.Season.ID {
+		q =
+			`
+			UPDATE game_season SET season_id = $1
+			WHERE game_id = $2 AND league_code = upper($3)
+			`
+		if _, err := store.Exec(q, assigned.Season.ID, game.ID, league); err != nil {
+			return err
 		}
 	}
 
-	if updateClubAway {
-		_, err := tx.Exec(updateClubAwayQuery.String(), updateClubAwayArgs...)
-		if err != nil {
-			tx.Rollback()
-			return game, err
-		}
+	q =
+		`
+		INSERT INTO game_club(game_id, league_code, season_id, club_id, score)
+		VALUES($1, upper($2), $3, $4, $5), ($1, upper($2), $3, $6, $7)
+		`
+	_, err := store.Exec(q, game.ID, league, assigned.Season.ID,
+		assigned.Home.ID, assigned.HomeScore, assigned.Away.ID, assigned.AwayScore)
+	if err != nil {
+		return err
 	}
 
-	if err := tx.Commit(); err != nil {
-		return game, err
+	// Players of a club replaced in the game leave the box score
+	q = "DELETE FROM game_player WHERE game_id = $1 AND club_id NOT IN ($2, $3)"
+	if _, err = store.Exec(q, game.ID, assigned.Home.ID, assigned.Away.ID); err != nil {
+		return err
 	}
 
-	return store.game(game.ID)
+	q = "INSERT INTO game_club_home(game_id, club_id) VALUES($1, $2)"
+	_, err = store.Exec(q, game.ID, assigned.Home.ID)
+	return err
+}
+
+// deleteGame deletes game, relying on the schema to cascade the
+// delete to its seasons, clubs, scores and resources
+func (store sqlStore) deleteGame(game Game) error {
+	q := "DELETE FROM game WHERE game_id = $1"
+	result, err := store.Exec(q, game.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
 }
 
-func (store store) resources(game Game) ([]Resource, error) {
+func (store sqlStore) resources(game Game) ([]Resource, error) {
 	var resources []Resource
 	q :=
 		`
 		SELECT resource_id, title, url
 		FROM resource
 		WHERE game_id = $1
+		ORDER BY resource_id asc
 		`
 	rows, err := store.Query(q, game.ID)
 	if err != nil {
@@ -512,7 +852,56 @@ func (store store) resources(game Game) ([]Resource, error) {
 	return resources, nil
 }
 
-func (store store) createResource(game Game, title, url string) (Resource, error) {
+// resourceBatch is how many games' resources are loaded per query,
+// well under the limit SQLite puts on bound parameters
+const resourceBatch = 500
+
+// resourcesByGame returns the resources of each of games, keyed by
+// game ID, with far fewer queries than calling resources for each
+func (store sqlStore) resourcesByGame(games []Game) (map[int][]Resource, error) {
+	resources := make(map[int][]Resource, len(games))
+	for start := 0; start < len(games); start += resourceBatch {
+		end := start + resourceBatch
+		if end > len(games) {
+			end = len(games)
+		}
+		batch := games[start:end]
+		args := make([]interface{}, len(batch))
+		for i, game := range batch {
+			args[i] = game.ID
+		}
+
+		q := fmt.Sprintf(
+			`
+			SELECT game_id, resource_id, title, url
+			FROM resource
+			WHERE game_id in (%s)
+			ORDER BY game_id asc, resource_id asc
+			`, ordinate(1, len(batch)))
+		rows, err := store.Query(q, args...)
+		if err != nil {
+			return resources, err
+		}
+
+		for rows.Next() {
+			var gameID int
+			var resource Resource
+			if err = rows.Scan(&gameID, &resource.ID, &resource.Title, &resource.URL); err != nil {
+				rows.Close()
+				return resources, err
+			}
+			resources[gameID] = append(resources[gameID], resource)
+		}
+		rows.Close()
+
+		if rows.Err() != nil {
+			return resources, rows.Err()
+		}
+	}
+	return resources, nil
+}
+
+func (store sqlStore) createResource(game Game, title, url string) (Resource, error) {
 	var r Resource
 	q :=
 		`
@@ -528,12 +917,740 @@ func (store store) createResource(game Game, title, url string) (Resource, error
 	return r, nil
 }
 
-func (store store) deleteResource(resource Resource) error {
+func (store sqlStore) deleteResource(resource Resource) error {
 	q := fmt.Sprintf("DELETE FROM resource WHERE resource_id = $1")
 	_, err := store.Exec(q, resource.ID)
 	return err
 }
 
+func (s sqlStore) periods(game Game) ([]Period, error) {
+	var periods []Period
+	q :=
+		`
+		SELECT period, home_score, away_score
+		FROM game_period
+		WHERE game_id = $1
+		ORDER BY period asc
+		`
+	rows, err := s.Query(q, game.ID)
+	if err != nil {
+		return periods, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var period Period
+		err = rows.Scan(&period.Number, &period.HomeScore, &period.AwayScore)
+		if err != nil {
+			return periods, err
+		}
+		periods = append(periods, period)
+	}
+
+	if rows.Err() != nil {
+		return periods, rows.Err()
+	}
+
+	return periods, nil
+}
+
+// setPeriods replaces the line score of game, checking it against the
+// stored final score. No periods removes the line score.
+func (s sqlStore) setPeriods(game Game, periods []Period) error {
+	return s.transact(func(tx sqlStore) error {
+		game, err := tx.game(game.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkPeriods(game, periods); err != nil {
+			return err
+		}
+
+		q := "DELETE FROM game_period WHERE game_id = $1"
+		if _, err := tx.Exec(q, game.ID); err != nil {
+			return err
+		}
+
+		q =
+			`
+			INSERT INTO game_period(game_id, period, home_score, away_score)
+			VALUES($1, $2, $3, $4)
+			`
+		for _, period := range periods {
+			_, err := tx.Exec(q, game.ID, period.Number, period.HomeScore, period.AwayScore)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (store sqlStore) players() ([]Player, error) {
+	q :=
+		`
+		SELECT player_id, player_name
+		FROM player
+		ORDER BY player_id asc
+		`
+	rows, err := store.Query(q)
+	if err != nil {
+		return nil, err
+	}
+	return scanPlayers(rows)
+}
+
+func (store sqlStore) player(id int) (Player, error) {
+	var player Player
+	q := "SELECT player_id, player_name FROM player WHERE player_id = $1"
+	err := store.QueryRow(q, id).Scan(&player.ID, &player.Name)
+	return player, err
+}
+
+func (store sqlStore) createPlayer(name string) (Player, error) {
+	q :=
+		`
+		INSERT INTO player(player_id, player_name)
+		SELECT coalesce(max(player_id), 0) + 1, $1
+		FROM player
+		RETURNING player_id, player_name
+		`
+	var player Player
+	err := store.QueryRow(q, name).Scan(&player.ID, &player.Name)
+	return player, err
+}
+
+// roster returns the players of club in season, ordered by name
+func (store sqlStore) roster(season Season, club Club) ([]Player, error) {
+	q :=
+		`
+		SELECT player.player_id, player.player_name
+		FROM season_roster
+		JOIN player ON player.player_id = season_roster.player_id
+		WHERE season_roster.season_id = $1 AND season_roster.club_id = $2
+		ORDER BY player.player_name asc, player.player_id asc
+		`
+	rows, err := store.Query(q, season.ID, club.ID)
+	if err != nil {
+		return nil, err
+	}
+	return scanPlayers(rows)
+}
+
+func scanPlayers(rows *sql.Rows) ([]Player, error) {
+	defer rows.Close()
+
+	var players []Player
+	for rows.Next() {
+		var player Player
+		if err := rows.Scan(&player.ID, &player.Name); err != nil {
+			return players, err
+		}
+		players = append(players, player)
+	}
+
+	if rows.Err() != nil {
+		return players, rows.Err()
+	}
+	return players, nil
+}
+
+func (store sqlStore) addRosterPlayer(season Season, club Club, player Player) error {
+	q :=
+		`
+		INSERT INTO season_roster(season_id, league_code, club_id, player_id)
+		VALUES($1, upper($2), $3, $4)
+		`
+	_, err := store.Exec(q, season.ID, season.League.Code, club.ID, player.ID)
+	return err
+}
+
+func (store sqlStore) removeRosterPlayer(season Season, club Club, player Player) error {
+	q := "DELETE FROM season_roster WHERE season_id = $1 AND club_id = $2 AND player_id = $3"
+	result, err := store.Exec(q, season.ID, club.ID, player.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("player %d is not on the roster of club %d", player.ID, club.ID)
+	}
+	return nil
+}
+
+// stats returns the box score columns of sport in order
+func (store sqlStore) stats(sport Sport) ([]Stat, error) {
+	q :=
+		`
+		SELECT stat_id, stat_code, stat_name
+		FROM stat
+		WHERE sport_id = $1
+		ORDER BY stat_id asc
+		`
+	var stats []Stat
+	rows, err := store.Query(q, sport.ID)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var stat Stat
+		if err = rows.Scan(&stat.ID, &stat.Code, &stat.Name); err != nil {
+			return stats, err
+		}
+		stats = append(stats, stat)
+	}
+
+	if rows.Err() != nil {
+		return stats, rows.Err()
+	}
+	return stats, nil
+}
+
+func (store sqlStore) createStat(sport Sport, code, name string) (Stat, error) {
+	q :=
+		`
+		INSERT INTO stat(stat_id, sport_id, stat_code, stat_name)
+		SELECT coalesce(max(stat_id), 0) + 1, $1, upper($2), $3
+		FROM stat
+		RETURNING stat_id, stat_code, stat_name
+		`
+	var stat Stat
+	err := store.QueryRow(q, sport.ID, code, name).Scan(&stat.ID, &stat.Code, &stat.Name)
+	return stat, err
+}
+
+// statLinesQuery selects stat lines with a row per stat, or a single
+// row of null stat for a player without any
+const statLinesQuery = `
+	SELECT game_player.game_id, player.player_id, player.player_name,
+	game_player.club_id, game_stat.stat_id, game_stat.value
+	FROM game_player
+	JOIN player ON player.player_id = game_player.player_id
+	LEFT JOIN game_stat
+		ON game_stat.game_id = game_player.game_id
+		AND game_stat.player_id = game_player.player_id
+	`
+
+// boxScore returns the stat lines of game ordered by player name
+func (store sqlStore) boxScore(game Game) ([]StatLine, error) {
+	q := statLinesQuery +
+		`
+		WHERE game_player.game_id = $1
+		ORDER BY player.player_name asc, player.player_id asc, game_stat.stat_id asc
+		`
+	return store.statLines(q, game.ID)
+}
+
+// playerLines returns the stat lines of player ordered by game ID
+func (store sqlStore) playerLines(player Player) ([]StatLine, error) {
+	q := statLinesQuery +
+		`
+		WHERE game_player.player_id = $1
+		ORDER BY game_player.game_id asc, game_stat.stat_id asc
+		`
+	return store.statLines(q, player.ID)
+}
+
+func (store sqlStore) statLines(q string, args ...interface{}) ([]StatLine, error) {
+	var lines []StatLine
+	rows, err := store.Query(q, args...)
+	if err != nil {
+		return lines, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line StatLine
+		var statID, value sql.NullInt64
+		err = rows.Scan(&line.GameID, &line.Player.ID, &line.Player.Name,
+			&line.ClubID, &statID, &value)
+		if err != nil {
+			return lines, err
+		}
+
+		last := len(lines) - 1
+		if last < 0 || lines[last].GameID != line.GameID || lines[last].Player.ID != line.Player.ID {
+			line.Values = make(map[int]int)
+			lines = append(lines, line)
+			last++
+		}
+		if statID.Valid {
+			lines[last].Values[int(statID.Int64)] = int(value.Int64)
+		}
+	}
+
+	if rows.Err() != nil {
+		return lines, rows.Err()
+	}
+	return lines, nil
+}
+
+// setStatLine replaces the stat line of line.Player in game. The
+// player must be on the season's roster of the club they played for,
+// which must be playing in game.
+func (s sqlStore) setStatLine(game Game, line StatLine) error {
+	if err := checkStatLine(game, line); err != nil {
+		return err
+	}
+
+	return s.transact(func(tx sqlStore) error {
+		var rostered bool
+		q :=
+			`
+			SELECT count(*) > 0 FROM season_roster
+			WHERE season_id = $1 AND club_id = $2 AND player_id = $3
+			`
+		if err := tx.QueryRow(q, game.Season.ID, line.ClubID, line.Player.ID).Scan(&rostered); err != nil {
+			return err
+		}
+		if !rostered {
+			return fmt.Errorf("player %d is not on the %d %s %s roster of club %d",
+				line.Player.ID, game.Season.Year, game.Season.League.Code, game.Season.Type, line.ClubID)
+		}
+
+		q =
+			`
+			SELECT stat.stat_id FROM stat
+			JOIN league ON league.sport_id = stat.sport_id
+			WHERE league.league_code = upper($1)
+			`
+		rows, err := tx.Query(q, game.Season.League.Code)
+		if err != nil {
+			return err
+		}
+		stats := make(map[int]bool)
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			stats[id] = true
+		}
+		rows.Close()
+		if rows.Err() != nil {
+			return rows.Err()
+		}
+
+		q = "DELETE FROM game_player WHERE game_id = $1 AND player_id = $2"
+		if _, err := tx.Exec(q, game.ID, line.Player.ID); err != nil {
+			return err
+		}
+
+		q = "INSERT INTO game_player(game_id, player_id, club_id) VALUES($1, $2, $3)"
+		if _, err := tx.Exec(q, game.ID, line.Player.ID, line.ClubID); err != nil {
+			return err
+		}
+
+		q = "INSERT INTO game_stat(game_id, player_id, stat_id, value) VALUES($1, $2, $3, $4)"
+		for _, id := range statIDs(line) {
+			if !stats[id] {
+				return fmt.Errorf("no %s stat %d", game.Season.League.Sport, id)
+			}
+			if _, err := tx.Exec(q, game.ID, line.Player.ID, id, line.Values[id]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (store sqlStore) deleteStatLine(game Game, player Player) error {
+	q := "DELETE FROM game_player WHERE game_id = $1 AND player_id = $2"
+	result, err := store.Exec(q, game.ID, player.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("player %d has no stat line in game %d", player.ID, game.ID)
+	}
+	return nil
+}
+
+func (store sqlStore) sports() ([]Sport, error) {
+	q :=
+		`
+		SELECT sport_id, sport_name, periods, period_name, overtime_name
+		FROM sport
+		ORDER BY sport_id asc
+		`
+	var sports []Sport
+	rows, err := store.Query(q)
+	if err != nil {
+		return sports, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sport Sport
+		err = rows.Scan(&sport.ID, &sport.Name,
+			&sport.Periods, &sport.PeriodName, &sport.OvertimeName)
+		if err != nil {
+			return sports, err
+		}
+		sports = append(sports, sport)
+	}
+
+	if rows.Err() != nil {
+		return sports, rows.Err()
+	}
+
+	return sports, nil
+}
+
+// clubs returns every iteration of every club
+func (store sqlStore) clubs() ([]Club, error) {
+	q :=
+		`
+		SELECT club_id, club_iteration, represents, coalesce(nickname, '')
+		FROM club
+		ORDER BY club_id asc, club_iteration asc
+		`
+	var clubs []Club
+	rows, err := store.Query(q)
+	if err != nil {
+		return clubs, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var club Club
+		err = rows.Scan(&club.ID, &club.Iteration,
+			&club.Represents, &club.Nickname)
+		if err != nil {
+			return clubs, err
+		}
+		clubs = append(clubs, club)
+	}
+
+	if rows.Err() != nil {
+		return clubs, rows.Err()
+	}
+	return clubs, nil
+}
+
+// New sports and clubs take the ID after the largest in use rather
+// than a generated one, as rows seeded with explicit IDs leave
+// generated IDs behind.
+
+func (store sqlStore) createSport(name string, periods int, periodName, overtimeName string) (Sport, error) {
+	q :=
+		`
+		INSERT INTO sport(sport_id, sport_name, periods, period_name, overtime_name)
+		SELECT coalesce(max(sport_id), 0) + 1, $1, $2, $3, $4
+		FROM sport
+		RETURNING sport_id, sport_name, periods, period_name, overtime_name
+		`
+	var sport Sport
+	err := store.QueryRow(q, name, periods, periodName, overtimeName).Scan(&sport.ID, &sport.Name,
+		&sport.Periods, &sport.PeriodName, &sport.OvertimeName)
+	return sport, err
+}
+
+// editSport replaces the period definition of sport
+func (store sqlStore) editSport(sport Sport) (Sport, error) {
+	q :=
+		`
+		UPDATE sport SET periods = $1, period_name = $2, overtime_name = $3
+		WHERE sport_id = $4
+		RETURNING sport_id, sport_name, periods, period_name, overtime_name
+		`
+	var edited Sport
+	err := store.QueryRow(q, sport.Periods, sport.PeriodName, sport.OvertimeName, sport.ID).Scan(
+		&edited.ID, &edited.Name, &edited.Periods, &edited.PeriodName, &edited.OvertimeName)
+	return edited, err
+}
+
+func (store sqlStore) createLeague(code, name string, sport Sport) (League, error) {
+	q :=
+		`
+		INSERT INTO league(league_code, league_name, sport_id)
+		VALUES(upper($1), $2, $3)
+		`
+	if _, err := store.Exec(q, code, name, sport.ID); err != nil {
+		return League{}, err
+	}
+	return store.league(strings.ToUpper(code))
+}
+
+func (store sqlStore) createClub(represents, nickname string) (Club, error) {
+	q :=
+		`
+		INSERT INTO club(club_id, club_iteration, represents, nickname)
+		SELECT coalesce(max(club_id), 0) + 1, 1, $1, $2
+		FROM club
+		RETURNING club_id, club_iteration, represents, nickname
+		`
+	var club Club
+	err := store.QueryRow(q, represents, nickname).
+		Scan(&club.ID, &club.Iteration, &club.Represents, &club.Nickname)
+	return club, err
+}
+
+// createClubIteration adds the next iteration of club, as when it
+// relocates or rebrands
+func (store sqlStore) createClubIteration(club Club, represents, nickname string) (Club, error) {
+	q :=
+		`
+		INSERT INTO club(club_id, club_iteration, represents, nickname)
+		SELECT club_id, max(club_iteration) + 1, $1, $2
+		FROM club
+		WHERE club_id = $3
+		GROUP BY club_id
+		RETURNING club_id, club_iteration, represents, nickname
+		`
+	var next Club
+	err := store.QueryRow(q, represents, nickname, club.ID).
+		Scan(&next.ID, &next.Iteration, &next.Represents, &next.Nickname)
+	if err == sql.ErrNoRows {
+		return next, fmt.Errorf("no club %d", club.ID)
+	}
+	return next, err
+}
+
+// setActiveClub makes club, at its given iteration, an active member
+// of league, whose new seasons it will play in
+func (store sqlStore) setActiveClub(league League, club Club) error {
+	q :=
+		`
+		INSERT INTO active_league_club(league_code, club_id, club_iteration)
+		VALUES(upper($1), $2, $3)
+		ON CONFLICT (league_code, club_id)
+		DO UPDATE SET club_iteration = excluded.club_iteration
+		`
+	_, err := store.Exec(q, league.Code, club.ID, club.Iteration)
+	return err
+}
+
+func (store sqlStore) removeActiveClub(league League, club Club) error {
+	q := "DELETE FROM active_league_club WHERE league_code = upper($1) AND club_id = $2"
+	result, err := store.Exec(q, league.Code, club.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("club %d is not active in %s", club.ID, league.Code)
+	}
+	return nil
+}
+
+// createSeason adds a season to league, played by the league's
+// active clubs, and optionally makes it the league's active season
+func (s sqlStore) createSeason(league League, year int, seasonType string, exhibition, active bool) (Season, error) {
+	season := Season{League: league, Year: year, Type: seasonType, Exhibition: exhibition}
+	err := s.transact(func(tx sqlStore) error {
+		q :=
+			`
+			INSERT INTO season(league_code, start_year, season_type, exhibition)
+			VALUES(upper($1), $2, $3, $4)
+			RETURNING season_id
+			`
+		err := tx.QueryRow(q, league.Code, year, seasonType, exhibition).Scan(&season.ID)
+		if err != nil {
+			return err
+		}
+
+		if active {
+			q =
+				`
+				INSERT INTO active_league_season(league_code, season_id)
+				VALUES(upper($1), $2)
+				ON CONFLICT (league_code)
+				DO UPDATE SET season_id = excluded.season_id
+				`
+			if _, err := tx.Exec(q, league.Code, season.ID); err != nil {
+				return err
+			}
+		}
+
+		q =
+			`
+			INSERT INTO season_club(season_id, league_code, club_id, club_iteration)
+			SELECT $1, league_code, club_id, club_iteration
+			FROM active_league_club
+			WHERE league_code = upper($2)
+			`
+		_, err = tx.Exec(q, season.ID, league.Code)
+		return err
+	})
+	return season, err
+}
+
+// empty reports whether the store holds no sports, leagues, clubs
+// or games
+func (store sqlStore) empty() (bool, error) {
+	q :=
+		`
+		SELECT NOT (
+			exists(SELECT 1 FROM sport) OR
+			exists(SELECT 1 FROM league) OR
+			exists(SELECT 1 FROM club) OR
+			exists(SELECT 1 FROM game) OR
+			exists(SELECT 1 FROM player))
+		`
+	var empty bool
+	err := store.QueryRow(q).Scan(&empty)
+	return empty, err
+}
+
+// The restore functions insert rows keeping the IDs they were
+// exported with. resetIdentities must be called once they are done
+// so that newly created rows do not collide with restored IDs.
+
+func (store sqlStore) restoreSport(sport Sport) error {
+	q :=
+		`
+		INSERT INTO sport(sport_id, sport_name, periods, period_name, overtime_name)
+		VALUES($1, $2, $3, $4, $5)
+		`
+	_, err := store.Exec(q, sport.ID, sport.Name, sport.Periods, sport.PeriodName, sport.OvertimeName)
+	return err
+}
+
+func (store sqlStore) restoreLeague(league League) error {
+	q :=
+		`
+		INSERT INTO league(league_code, league_name, sport_id)
+		SELECT upper($1), $2, sport_id
+		FROM sport
+		WHERE sport_name = $3
+		`
+	result, err := store.Exec(q, league.Code, league.Name, league.Sport)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("league %s: no sport %s", league.Code, league.Sport)
+	}
+	return nil
+}
+
+func (store sqlStore) restoreClub(club Club) error {
+	q :=
+		`
+		INSERT INTO club(club_id, club_iteration, represents, nickname)
+		VALUES($1, $2, $3, $4)
+		`
+	_, err := store.Exec(q, club.ID, club.Iteration, club.Represents, club.Nickname)
+	return err
+}
+
+func (store sqlStore) restoreActiveClub(league League, club Club) error {
+	q :=
+		`
+		INSERT INTO active_league_club(league_code, club_id, club_iteration)
+		VALUES(upper($1), $2, $3)
+		`
+	_, err := store.Exec(q, league.Code, club.ID, club.Iteration)
+	return err
+}
+
+func (store sqlStore) restoreSeason(season Season, active bool) error {
+	q :=
+		`
+		INSERT INTO season(season_id, league_code, start_year, season_type, exhibition)
+		VALUES($1, upper($2), $3, $4, $5)
+		`
+	_, err := store.Exec(q, season.ID, season.League.Code,
+		season.Year, season.Type, season.Exhibition)
+	if err != nil || !active {
+		return err
+	}
+
+	q =
+		`
+		INSERT INTO active_league_season(league_code, season_id)
+		VALUES(upper($1), $2)
+		`
+	_, err = store.Exec(q, season.League.Code, season.ID)
+	return err
+}
+
+func (store sqlStore) restoreSeasonClub(season Season, club Club) error {
+	q :=
+		`
+		INSERT INTO season_club(season_id, league_code, club_id, club_iteration)
+		VALUES($1, upper($2), $3, $4)
+		`
+	_, err := store.Exec(q, season.ID, season.League.Code, club.ID, club.Iteration)
+	return err
+}
+
+func (s sqlStore) restoreGame(game Game) error {
+	return s.transact(func(tx sqlStore) error {
+		league := game.Season.League.Code
+		q :=
+			`
+			INSERT INTO game(game_id, league_code, game_date, title, venue, status, game_time)
+			VALUES($1, upper($2), $3, $4, $5, $6, $7)
+			`
+		_, err := tx.Exec(q, game.ID, league, dateArg(game.Date), game.Title, game.Venue,
+			game.Status, timeArg(game.Time))
+		if err != nil {
+			return err
+		}
+
+		q =
+			`
+			INSERT INTO game_season(game_id, league_code, season_id)
+			VALUES($1, upper($2), $3)
+			`
+		if _, err = tx.Exec(q, game.ID, league, game.Season.ID); err != nil {
+			return err
+		}
+
+		q =
+			`
+			INSERT INTO game_club(game_id, league_code, season_id, club_id, score)
+			VALUES($1, upper($2), $3, $4, $5), ($1, upper($2), $3, $6, $7)
+			`
+		_, err = tx.Exec(q, game.ID, league, game.Season.ID,
+			game.Home.ID, game.HomeScore, game.Away.ID, game.AwayScore)
+		if err != nil {
+			return err
+		}
+
+		q = "INSERT INTO game_club_home(game_id, club_id) VALUES($1, $2)"
+		_, err = tx.Exec(q, game.ID, game.Home.ID)
+		return err
+	})
+}
+
+func (store sqlStore) restoreResource(game Game, resource Resource) error {
+	q :=
+		`
+		INSERT INTO resource(resource_id, game_id, title, url)
+		VALUES($1, $2, $3, $4)
+		`
+	_, err := store.Exec(q, resource.ID, game.ID, resource.Title, resource.URL)
+	return err
+}
+
+func (store sqlStore) restorePlayer(player Player) error {
+	q := "INSERT INTO player(player_id, player_name) VALUES($1, $2)"
+	_, err := store.Exec(q, player.ID, player.Name)
+	return err
+}
+
+func (store sqlStore) restoreStat(sport Sport, stat Stat) error {
+	q :=
+		`
+		INSERT INTO stat(stat_id, sport_id, stat_code, stat_name)
+		VALUES($1, $2, $3, $4)
+		`
+	_, err := store.Exec(q, stat.ID, sport.ID, stat.Code, stat.Name)
+	return err
+}
+
 // seasonQuery creates an SQL query string and a list of []interface{}
 // arguments suitable for Query() from the given seasonFilter
 func seasonQuery(sf seasonFilter) (string, []interface{}) {
@@ -593,12 +1710,13 @@ func gameQuery(gf gameFilter) (string, []interface{}) {
 		SELECT
 			game_id, season_id, sport_name,
 			league_code, league_name, start_year,
-			season_type, exhibition, game_date::text,
+			season_type, exhibition, CAST(game_date AS text),
 			coalesce(title, ''), coalesce(venue, ''),
 			home_id, home_iteration,
 			home_represents, home_nickname, home_score,
 			away_id, away_iteration, away_represents,
-			away_nickname, away_score
+			away_nickname, away_score, status,
+			coalesce(substr(CAST(game_time AS text), 1, 5), '')
 		FROM game_view
 		`)
 
@@ -614,8 +1732,15 @@ func gameQuery(gf gameFilter) (string, []interface{}) {
 		arg += len(gf.IDs)
 	}
 
-	// Filter by clubs playing
-	if len(gf.Clubs) > 0 {
+	// Filter by clubs playing, either any or all of them
+	if len(gf.Clubs) > 0 && gf.AllClubs {
+		for range gf.Clubs {
+			where = append(
+				where,
+				fmt.Sprintf("game_id in (select game_id from game_club where club_id = $%d)", arg))
+			arg++
+		}
+	} else if len(gf.Clubs) > 0 {
 		where = append(
 			where,
 			fmt.Sprintf("game_id in (select game_id from game_club where club_id in (%s))",
@@ -671,11 +1796,11 @@ func gameQuery(gf gameFilter) (string, []interface{}) {
 }
 
 // ordinate returns a string of the form
-// $start $(start + 1) ... $(start + len - 1)
+// $start, $(start + 1), ..., $(start + len - 1)
 func ordinate(start, len int) string {
-	var b strings.Builder
-	for i := start; i < start+len; i++ {
-		fmt.Fprintf(&b, "$%d ", i)
+	placeholders := make([]string, len)
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", start+i)
 	}
-	return b.String()
+	return strings.Join(placeholders, ", ")
 }
@@ -0,0 +1,267 @@
+package internal
+
+import (
+	"database/sql"
+	_ "github.com/mattn/go-sqlite3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testArchive is two leagues' worth of history. The NBA playoffs are
+// played by only two of its clubs, and games 2 and 3 share a date so
+// the order of games on the same day is checked too.
+const testArchive = `{
+	"version": 1,
+	"sports": [{"id": 1, "name": "Basketball"}, {"id": 2, "name": "Football"}],
+	"leagues": [
+		{"code": "NBA", "name": "National Basketball Association", "sport": "Basketball", "active_season": 1,
+			"active_clubs": [{"id": 1, "iteration": 1}, {"id": 2, "iteration": 1}, {"id": 3, "iteration": 1}]},
+		{"code": "NFL", "name": "National Football League", "sport": "Football", "active_season": 4,
+			"active_clubs": [{"id": 4, "iteration": 1}, {"id": 5, "iteration": 1}]}
+	],
+	"clubs": [
+		{"id": 1, "iteration": 1, "represents": "Atlanta", "nickname": "Hawks"},
+		{"id": 2, "iteration": 1, "represents": "Boston", "nickname": "Celtics"},
+		{"id": 3, "iteration": 1, "represents": "Chicago", "nickname": "Bulls"},
+		{"id": 4, "iteration": 1, "represents": "Dallas", "nickname": "Cowboys"},
+		{"id": 5, "iteration": 1, "represents": "Denver", "nickname": "Broncos"}
+	],
+	"seasons": [
+		{"id": 1, "league": "NBA", "year": 2022, "type": "Season",
+			"clubs": [{"id": 1, "iteration": 1}, {"id": 2, "iteration": 1}, {"id": 3, "iteration": 1}]},
+		{"id": 2, "league": "NBA", "year": 2021, "type": "Season",
+			"clubs": [{"id": 1, "iteration": 1}, {"id": 2, "iteration": 1}, {"id": 3, "iteration": 1}]},
+		{"id": 3, "league": "NBA", "year": 2022, "type": "Playoffs",
+			"clubs": [{"id": 1, "iteration": 1}, {"id": 2, "iteration": 1}]},
+		{"id": 4, "league": "NFL", "year": 2022, "type": "Season",
+			"clubs": [{"id": 4, "iteration": 1}, {"id": 5, "iteration": 1}]}
+	],
+	"games": [
+		{"id": 1, "season": 1, "date": "2022-01-01", "home": 1, "home_score": 100, "away": 2, "away_score": 90,
+			"resources": [{"id": 2, "title": "Box score", "url": "https://example.com/1/box"},
+				{"id": 1, "title": "Recap", "url": "https://example.com/1"}]},
+		{"id": 2, "season": 1, "date": "2022-01-03", "home": 2, "home_score": 101, "away": 3, "away_score": 99},
+		{"id": 3, "season": 1, "date": "2022-01-03", "home": 3, "home_score": 80, "away": 1, "away_score": 85},
+		{"id": 4, "season": 2, "date": "2021-02-01", "home": 1, "home_score": 99, "away": 3, "away_score": 98},
+		{"id": 5, "season": 3, "date": "2022-04-20", "home": 1, "home_score": 101, "away": 2, "away_score": 103,
+			"resources": [{"id": 3, "title": "Recap", "url": "https://example.com/5"}]},
+		{"id": 6, "season": 4, "date": "2022-09-10", "home": 4, "home_score": 24, "away": 5, "away_score": 17}
+	]
+}`
+
+// restoredStores returns a memory store and an SQLite store, each
+// restored from testArchive, so their queries can be compared
+func restoredStores(t *testing.T) map[string]store {
+	t.Helper()
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "recap.db")+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	sqlite, err := newStore("sqlite3", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrations, err := loadMigrations(os.DirFS("../sql/migrations"), migrationDirs["sqlite3"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrate(sqlite.(migrator), migrations); err != nil {
+		t.Fatal(err)
+	}
+
+	stores := map[string]store{"memory": newMemoryStore(), "sqlite3": sqlite}
+	for name, s := range stores {
+		a, err := readArchive(strings.NewReader(testArchive))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := restoreArchive(s, a); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	return stores
+}
+
+func TestStoreGames(t *testing.T) {
+	nba := League{Code: "NBA"}
+	hawks, celtics, cowboys := Club{ID: 1}, Club{ID: 2}, Club{ID: 4}
+	limited := func(filter gameFilter, n int) gameFilter {
+		filter.SetLimit(n)
+		return filter
+	}
+
+	tests := []struct {
+		name   string
+		filter gameFilter
+		want   []int
+	}{
+		{"newest first", gameFilter{}, []int{6, 5, 3, 2, 1, 4}},
+		{"limit", limited(gameFilter{}, 2), []int{6, 5}},
+		{"limit beyond games", limited(gameFilter{}, 10), []int{6, 5, 3, 2, 1, 4}},
+		{"league", gameFilter{Leagues: []League{nba}}, []int{5, 3, 2, 1, 4}},
+		{"season", gameFilter{Seasons: []Season{{ID: 1}}}, []int{3, 2, 1}},
+		{"seasons", gameFilter{Seasons: []Season{{ID: 1}, {ID: 3}}}, []int{5, 3, 2, 1}},
+		{"club", gameFilter{Clubs: []Club{hawks}}, []int{5, 3, 1, 4}},
+		{"clubs", gameFilter{Clubs: []Club{hawks, celtics}}, []int{5, 3, 2, 1, 4}},
+		{"all clubs", gameFilter{Clubs: []Club{hawks, celtics}, AllClubs: true}, []int{5, 1}},
+		{"all clubs, limited", limited(gameFilter{Clubs: []Club{hawks, celtics}, AllClubs: true}, 1), []int{5}},
+		{"IDs", gameFilter{IDs: []int{1, 4, 6}}, []int{6, 1, 4}},
+		{"club in season, limited", limited(gameFilter{Clubs: []Club{hawks}, Seasons: []Season{{ID: 1}}}, 1), []int{3}},
+		{"nothing matches", gameFilter{Clubs: []Club{cowboys}, Leagues: []League{nba}}, nil},
+	}
+
+	stores := restoredStores(t)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results := make(map[string][]Game)
+			for name, s := range stores {
+				games, err := s.games(test.filter)
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				var ids []int
+				for _, game := range games {
+					ids = append(ids, game.ID)
+				}
+				if !reflect.DeepEqual(ids, test.want) {
+					t.Errorf("%s: got games %v, want %v", name, ids, test.want)
+				}
+				results[name] = games
+			}
+			if !reflect.DeepEqual(results["memory"], results["sqlite3"]) {
+				t.Errorf("stores differ\nmemory:  %+v\nsqlite3: %+v", results["memory"], results["sqlite3"])
+			}
+		})
+	}
+}
+
+func TestStoreSeasons(t *testing.T) {
+	filter := func(league string, club, limit int) seasonFilter {
+		var sf seasonFilter
+		if league != "" {
+			sf.SetLeague(League{Code: league})
+		}
+		if club != 0 {
+			sf.SetClub(Club{ID: club})
+		}
+		if limit != 0 {
+			sf.SetLimit(limit)
+		}
+		return sf
+	}
+
+	tests := []struct {
+		name   string
+		filter seasonFilter
+		want   []int
+	}{
+		{"by year, then newest", filter("", 0, 0), []int{2, 4, 3, 1}},
+		{"limit", filter("", 0, 2), []int{2, 4}},
+		{"league", filter("NBA", 0, 0), []int{2, 3, 1}},
+		{"club", filter("", 3, 0), []int{2, 1}},
+		{"club, limited", filter("", 1, 2), []int{2, 3}},
+		{"club outside league", filter("NBA", 4, 0), nil},
+	}
+
+	stores := restoredStores(t)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results := make(map[string][]Season)
+			for name, s := range stores {
+				seasons, err := s.seasons(test.filter)
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				var ids []int
+				for _, season := range seasons {
+					ids = append(ids, season.ID)
+				}
+				if !reflect.DeepEqual(ids, test.want) {
+					t.Errorf("%s: got seasons %v, want %v", name, ids, test.want)
+				}
+				results[name] = seasons
+			}
+			if !reflect.DeepEqual(results["memory"], results["sqlite3"]) {
+				t.Errorf("stores differ\nmemory:  %+v\nsqlite3: %+v", results["memory"], results["sqlite3"])
+			}
+		})
+	}
+}
+
+func TestStoreResourcesByGame(t *testing.T) {
+	for name, s := range restoredStores(t) {
+		t.Run(name, func(t *testing.T) {
+			games, err := s.games(gameFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			resources, err := s.resourcesByGame(games)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, game := range games {
+				want, err := s.resources(game)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := resources[game.ID]; !reflect.DeepEqual(got, want) {
+					t.Errorf("game %d: got resources %+v, want %+v", game.ID, got, want)
+				}
+			}
+			if got := len(resources[1]); got != 2 {
+				t.Errorf("got %d resources of game 1, want 2", got)
+			}
+		})
+	}
+}
+
+func TestStoreEditGameStatus(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(edit *gameEdit)
+		valid bool
+	}{
+		{"postponed keeping the score", func(edit *gameEdit) {
+			edit.SetStatus(statusPostponed)
+		}, false},
+		{"postponed without a score", func(edit *gameEdit) {
+			edit.SetStatus(statusPostponed)
+			edit.SetHomeScore(0)
+			edit.SetAwayScore(0)
+		}, true},
+		{"postponed with a line score", func(edit *gameEdit) {
+			edit.SetStatus(statusPostponed)
+			edit.SetHomeScore(0)
+			edit.SetAwayScore(0)
+			edit.SetPeriods([]Period{{Number: 1}})
+		}, false},
+		{"forfeit keeping the score", func(edit *gameEdit) {
+			edit.SetStatus(statusForfeit)
+		}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, s := range restoredStores(t) {
+				game, err := s.game(1)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var edit gameEdit
+				test.edit(&edit)
+				if _, err := s.editGame(game, edit); (err == nil) != test.valid {
+					t.Errorf("%s: got error %v, want valid %v", name, err, test.valid)
+				}
+				if stored, err := s.game(1); err != nil {
+					t.Fatal(err)
+				} else if !test.valid && stored.Status != game.Status {
+					t.Errorf("%s: rejected edit left status %s", name, stored.Status)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// templateKinds are the kinds of page each template is used to write.
+// A template not listed here may be used by any page.
+var templateKinds = map[string]pageKind{
+	"game.tmpl":      gamePages,
+	"matchup.tmpl":   matchupPages,
+	"player.tmpl":    playerPages,
+	"index.tmpl":     indexPages,
+	"standings.tmpl": indexPages,
+	"sidebar.tmpl":   indexPages,
+	"header.tmpl":    allPages,
+}
+
+// sourceFile is the state of a source file when it was last installed
+type sourceFile struct {
+	size     int64
+	modified time.Time
+}
+
+// watcher copies the templates and static assets under a source
+// directory, laid out like web, into the recap directory whenever they
+// change, where they override those built in, and rebuilds the pages
+// written from them. It polls rather than relying on file system
+// events, so it works the same on every platform.
+type watcher struct {
+	cli   CLI
+	src   string
+	files map[string]sourceFile
+
+	// pending holds the kinds of page to rebuild, kept until they are
+	// written so a template error does not lose earlier changes
+	pending pageKind
+}
+
+func newWatcher(cli CLI, src string) *watcher {
+	return &watcher{
+		cli:   cli,
+		src:   src,
+		files: make(map[string]sourceFile),
+	}
+}
+
+// watch checks the source directory every interval until the process
+// is stopped. Everything is installed on the first check, so the site
+// starts out up to date with the sources.
+func (w *watcher) watch(interval time.Duration) {
+	for {
+		if err := w.check(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// check installs whatever changed since the last check and rebuilds the
+// affected pages. Errors are returned to be reported, and the pages are
+// tried again on the next change.
+func (w *watcher) check() error {
+	templates, err := w.changed(filepath.Join(w.src, "templates"), "*.tmpl")
+	if err != nil {
+		return err
+	}
+	assets, err := w.changed(filepath.Join(w.src, "static"), "*")
+	if err != nil {
+		return err
+	}
+	if len(templates)+len(assets) == 0 {
+		return nil
+	}
+
+	dg := &w.cli.docGen
+	for _, template := range templates {
+		if err := w.install(template, dg.templatePath); err != nil {
+			return err
+		}
+		if kinds, found := templateKinds[filepath.Base(template)]; found {
+			w.pending |= kinds
+		} else {
+			w.pending = allPages
+		}
+	}
+	for _, asset := range assets {
+		if err := w.install(asset, filepath.Join(dg.assets.recapPath, "static")); err != nil {
+			return err
+		}
+		w.pending |= staticAssets
+	}
+
+	if _, err := dg.reloadTemplates(); err != nil {
+		return err
+	}
+	fmt.Printf("Reloaded %s\n", strings.Join(baseNames(append(templates, assets...)), ", "))
+
+	dg.manifest.startRun()
+	err = w.cli.writePages(w.pending)
+	written, skipped, _ := dg.manifest.counts()
+	if written > 0 {
+		if saveErr := dg.saveManifest(); err == nil {
+			err = saveErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+	w.pending = 0
+	fmt.Printf("Wrote %d pages and skipped %d unchanged\n", written, skipped)
+	return nil
+}
+
+// changed lists the files in dir matching pattern that are new or have
+// changed since they were last seen
+func (w *watcher) changed(dir, pattern string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		file := sourceFile{info.Size(), info.ModTime()}
+		if last, found := w.files[path]; found && last == file {
+			continue
+		}
+		w.files[path] = file
+		changed = append(changed, path)
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// install copies the file at path into dir, where it overrides the
+// built in asset of the same name
+func (w *watcher) install(path, dir string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return replaceFile(filepath.Join(dir, filepath.Base(path)), data)
+}
+
+// replaceFile writes data to a temporary file renamed over path, so the
+// file at path is never read half written
+func replaceFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := fmt.Sprintf("%s.tmp", path)
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func baseNames(paths []string) []string {
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = filepath.Base(path)
+	}
+	return names
+}
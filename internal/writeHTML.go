@@ -5,64 +5,105 @@ import (
 	"fmt"
 	"html/template"
 	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
-	text "text/template"
 )
 
 type documentGenerator struct {
-	staticPath      string
-	templatePath    string
-	indexTemplate   *template.Template
-	gameTemplate    *template.Template
-	sidebarTemplate *text.Template
+	staticPath        string
+	templatePath      string
+	indexTemplate     *template.Template
+	gameTemplate      *template.Template
+	standingsTemplate *template.Template
+	matchupTemplate   *template.Template
+	playerTemplate    *template.Template
+	assets            siteAssets
+	version           string
+	manifest          *manifest
+	builds            buildPaths
+	dryRun            bool
 }
 
-func (dg *documentGenerator) Initialize(recapPath string) {
+func (dg *documentGenerator) Initialize(recapPath string, web fs.FS) error {
 	dg.staticPath = filepath.Join(recapPath, "www")
 	dg.templatePath = filepath.Join(recapPath, "templates")
+	dg.assets = newSiteAssets(web, recapPath)
+	if err := dg.parseTemplates(); err != nil {
+		return err
+	}
 
-	indexTemplate := filepath.Join(dg.templatePath, "index.tmpl")
-	gameTemplate := filepath.Join(dg.templatePath, "game.tmpl")
-	sidebarTemplate := filepath.Join(dg.templatePath, "sidebar.tmpl")
-	headerTemplate := filepath.Join(dg.templatePath, "header.tmpl")
-
-	funcMap := template.FuncMap{"GamePath": gamePath, "DateShort": dateShort, "DateLong": dateLong}
-	dg.indexTemplate = template.Must(template.New("index.tmpl").Funcs(funcMap).ParseFiles(indexTemplate, headerTemplate))
-	dg.gameTemplate = template.Must(template.New("game.tmpl").Funcs(funcMap).ParseFiles(gameTemplate, headerTemplate))
-	dg.sidebarTemplate = text.Must(text.New("sidebar.tmpl").ParseFiles(sidebarTemplate))
-}
-
-func (dg documentGenerator) gamePath(game Game) string {
-	return filepath.Join(dg.staticPath, gamePath(game))
-}
-
-func (dg documentGenerator) clubPath(club Club, season Season) string {
-	return filepath.Join(dg.staticPath, clubPath(club, season))
-}
-
-func (dg documentGenerator) leaguePath(league League) string {
-	return filepath.Join(dg.staticPath, leaguePath(league))
+	dg.builds = newBuildPaths(recapPath)
+	dg.manifest = loadManifest(filepath.Join(recapPath, "manifest.json"), dg.version)
+	return nil
 }
 
-func (dg documentGenerator) indexPath() string {
-	return filepath.Join(dg.staticPath, indexPath())
+// reloadTemplates parses the page templates again, so that pages are
+// written afresh if they changed, and reports whether they did
+func (dg *documentGenerator) reloadTemplates() (bool, error) {
+	version := dg.version
+	if err := dg.parseTemplates(); err != nil {
+		return false, err
+	}
+	dg.manifest.setVersion(dg.version)
+	return dg.version != version, nil
 }
 
-func (dg documentGenerator) clubSidebarPath(club Club, league League) string {
-	return filepath.Join(dg.templatePath,
-		fmt.Sprintf("sidebar/%s/%d.tmpl", league.Code, club.ID))
-}
+// parseTemplates parses the page templates, leaving those already
+// parsed in place if any fails to parse
+func (dg *documentGenerator) parseTemplates() error {
+	sources, err := dg.assets.templates()
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{"index.tmpl", "game.tmpl", "standings.tmpl",
+		"matchup.tmpl", "player.tmpl", "sidebar.tmpl", "header.tmpl"} {
+		if _, found := sources[name]; !found {
+			return fmt.Errorf("missing template %s", name)
+		}
+	}
 
-func (dg documentGenerator) leagueSidebarPath(league League) string {
-	return filepath.Join(dg.templatePath,
-		fmt.Sprintf("sidebar/%s/index.tmpl", league.Code))
-}
+	funcMap := template.FuncMap{"GamePath": gamePath, "ClubPath": clubPath, "PlayerPath": playerPath,
+		"DateShort": dateShort, "DateLong": dateLong}
+	// Every page includes the header, and index pages the sidebar
+	parse := func(name string, includes ...string) (*template.Template, error) {
+		page, err := template.New(name).Funcs(funcMap).Parse(sources[name])
+		if err != nil {
+			return nil, err
+		}
+		for _, include := range append(includes, "header.tmpl") {
+			if _, err = page.New(include).Parse(sources[include]); err != nil {
+				return nil, err
+			}
+		}
+		return page, nil
+	}
 
-func (dg documentGenerator) indexSidebarPath() string {
-	return filepath.Join(dg.templatePath, "sidebar/index.tmpl")
+	parsed := *dg
+	if parsed.indexTemplate, err = parse("index.tmpl", "sidebar.tmpl"); err != nil {
+		return err
+	}
+	if parsed.gameTemplate, err = parse("game.tmpl"); err != nil {
+		return err
+	}
+	if parsed.standingsTemplate, err = parse("standings.tmpl"); err != nil {
+		return err
+	}
+	if parsed.matchupTemplate, err = parse("matchup.tmpl"); err != nil {
+		return err
+	}
+	if parsed.playerTemplate, err = parse("player.tmpl"); err != nil {
+		return err
+	}
+	parsed.version = generatorVersion(sources)
+	*dg = parsed
+	return nil
 }
 
+// createFile creates the file at path and any missing directories. An
+// existing file is removed rather than truncated, since it may be hard
+// linked from an earlier build.
 func createFile(path string) (*os.File, error) {
 	dir := filepath.Dir(path)
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
@@ -73,141 +114,245 @@ func createFile(path string) (*os.File, error) {
 		return nil, err
 	}
 
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
 	return os.Create(path)
 }
 
-func (dg documentGenerator) clubSidebar(club Club, seasons []Season, league League) error {
-	path := dg.clubSidebarPath(club, league)
-	file, err := createFile(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+type nopWriteCloser struct {
+	io.Writer
+}
 
-	doc := document{dg, file}
-	return doc.clubSidebar(club, seasons)
+func (nopWriteCloser) Close() error {
+	return nil
 }
 
-func (dg documentGenerator) leagueSidebar(league League, season Season, clubs []Club) error {
-	path := dg.leagueSidebarPath(league)
-	file, err := createFile(path)
-	if err != nil {
-		return err
+// create opens path for writing. In a dry run the path is only
+// reported and whatever is written is discarded.
+func (dg documentGenerator) create(path string) (io.WriteCloser, error) {
+	if dg.dryRun {
+		fmt.Printf("Would write %s\n", path)
+		return nopWriteCloser{io.Discard}, nil
 	}
-	defer file.Close()
+	return createFile(path)
+}
 
-	doc := document{dg, file}
-	return doc.leagueSidebar(season, clubs)
+// remove deletes the page at sitePath under the site root and its
+// gzipped copy, if they exist, and drops it from the manifest. In a dry
+// run the paths are only reported.
+func (dg documentGenerator) remove(sitePath string) error {
+	path := filepath.Join(dg.staticPath, sitePath)
+	for _, p := range []string{path, fmt.Sprintf("%s.gz", path)} {
+		if dg.dryRun {
+			fmt.Printf("Would remove %s\n", p)
+			continue
+		}
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	dg.manifest.forget(sitePath)
+	return nil
 }
 
-func (dg documentGenerator) indexSidebar(leagues []League) error {
-	path := dg.indexSidebarPath()
-	file, err := createFile(path)
+// page writes the file at sitePath under the site root, with a
+// gzipped copy, by calling render. If the manifest shows the file was
+// last written from the same input, it is left alone.
+func (dg documentGenerator) page(sitePath string, input any, render func(io.Writer) error) error {
+	hash, err := dg.manifest.hash(input)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	doc := document{dg, file}
-	return doc.indexSidebar(leagues)
-}
-
-func (dg documentGenerator) gamePage(game Game, resources []Resource) error {
-	var (
-		file   *os.File
-		fileGZ *os.File
-		err    error
-	)
+	path := filepath.Join(dg.staticPath, sitePath)
+	if dg.manifest.unchanged(sitePath, path, hash) {
+		dg.manifest.skip(sitePath)
+		return nil
+	}
 
-	path := dg.gamePath(game)
-	if file, err = createFile(path); err != nil {
+	file, err := dg.create(path)
+	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	pathGZ := fmt.Sprintf("%s.gz", path)
-	if fileGZ, err = createFile(pathGZ); err != nil {
+	fileGZ, err := dg.create(fmt.Sprintf("%s.gz", path))
+	if err != nil {
 		return err
 	}
 	defer fileGZ.Close()
 	gz, _ := gzip.NewWriterLevel(fileGZ, gzip.BestCompression)
-	defer gz.Close()
-
-	doc := document{dg, io.MultiWriter(file, gz)}
-	return doc.game(game, resources)
-}
 
-func (dg documentGenerator) clubIndex(club Club, season Season, games []Game) error {
-	var (
-		file   *os.File
-		fileGZ *os.File
-		err    error
-	)
-
-	path := dg.clubPath(club, season)
-	if file, err = createFile(path); err != nil {
+	if err = render(io.MultiWriter(file, gz)); err != nil {
 		return err
 	}
-	defer file.Close()
-
-	pathGZ := fmt.Sprintf("%s.gz", path)
-	if fileGZ, err = createFile(pathGZ); err != nil {
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	if err = file.Close(); err != nil {
 		return err
 	}
-	defer fileGZ.Close()
-	gz, _ := gzip.NewWriterLevel(fileGZ, gzip.BestCompression)
-	defer gz.Close()
 
-	doc := document{dg, io.MultiWriter(file, gz)}
-	return doc.club(club, season, games)
+	if dg.dryRun {
+		dg.manifest.wouldWrite(sitePath)
+		return nil
+	}
+	return dg.manifest.record(sitePath, path, hash)
 }
 
-func (dg documentGenerator) leagueIndex(league League, games []Game) error {
-	var (
-		file   *os.File
-		fileGZ *os.File
-		err    error
-	)
+func (dg documentGenerator) gamePage(game Game, resources []Resource, line lineScore, scores []boxScore) error {
+	input := []any{game, resources, line, scores}
+	return dg.page(gamePath(game), input, func(w io.Writer) error {
+		doc := document{dg, w}
+		return doc.game(game, resources, line, scores)
+	})
+}
 
-	path := dg.leaguePath(league)
-	if file, err = createFile(path); err != nil {
-		return err
-	}
-	defer file.Close()
+// clubIndex writes the index of a club's games in season. seasons are
+// those the club played in the league, for the sidebar.
+func (dg documentGenerator) clubIndex(club Club, season Season, games []Game, seasons []Season) error {
+	sidebar := clubSidebar(club, seasons)
+	input := []any{club, season, games, sidebar}
+	return dg.page(clubPath(club, season), input, func(w io.Writer) error {
+		doc := document{dg, w}
+		return doc.club(club, season, games, sidebar)
+	})
+}
 
-	pathGZ := fmt.Sprintf("%s.gz", path)
-	if fileGZ, err = createFile(pathGZ); err != nil {
-		return err
-	}
-	defer fileGZ.Close()
-	gz, _ := gzip.NewWriterLevel(fileGZ, gzip.BestCompression)
-	defer gz.Close()
+func (dg documentGenerator) standingsPage(season Season, lines []standing, seasons []Season) error {
+	input := []any{season, lines, seasons}
+	return dg.page(standingsPath(season), input, func(w io.Writer) error {
+		doc := document{dg, w}
+		return doc.standings(season, lines, seasons)
+	})
+}
+
+func (dg documentGenerator) matchupPage(league League, m matchup) error {
+	input := []any{league, m}
+	return dg.page(matchupPath(league, m.A, m.B), input, func(w io.Writer) error {
+		doc := document{dg, w}
+		return doc.matchup(league, m)
+	})
+}
+
+func (dg documentGenerator) playerPage(player Player, seasons []playerSeason) error {
+	input := []any{player, seasons}
+	return dg.page(playerPath(player), input, func(w io.Writer) error {
+		doc := document{dg, w}
+		return doc.player(player, seasons)
+	})
+}
+
+// leagueIndex writes the index of a league's recent games. season,
+// clubs and seasons are its active season and clubs and all its
+// seasons, for the sidebar.
+func (dg documentGenerator) leagueIndex(league League, games []Game, season Season, clubs []Club, seasons []Season) error {
+	sidebar := leagueSidebar(season, clubs, seasons)
+	input := []any{league, games, sidebar}
+	return dg.page(leaguePath(league), input, func(w io.Writer) error {
+		doc := document{dg, w}
+		return doc.league(league, games, sidebar)
+	})
+}
+
+func (dg documentGenerator) index(games []Game, leagues []League) error {
+	sidebar := indexSidebar(leagues)
+	input := []any{games, sidebar}
+	return dg.page(indexPath(), input, func(w io.Writer) error {
+		doc := document{dg, w}
+		return doc.index(games, sidebar)
+	})
+}
 
-	doc := document{dg, io.MultiWriter(file, gz)}
-	return doc.league(league, games)
+// feed writes feed, with a gzipped copy, next to the page at pagePath
+func (dg documentGenerator) feed(pagePath string, feed atomFeed) error {
+	return dg.page(feedPath(pagePath), feed, func(w io.Writer) error {
+		return writeFeed(w, feed)
+	})
 }
 
-func (dg documentGenerator) index(games []Game) error {
-	var (
-		file   *os.File
-		fileGZ *os.File
-		err    error
-	)
+// calendar writes a calendar of games, with a gzipped copy, at path
+// under the site root
+func (dg documentGenerator) calendar(path, name string, games []Game) error {
+	input := []any{name, games}
+	return dg.page(path, input, func(w io.Writer) error {
+		return writeCalendar(w, name, games)
+	})
+}
+
+func (dg documentGenerator) clubCalendar(club Club, season Season, games []Game) error {
+	name := fmt.Sprintf("%s %s: %d %s %s", club.Represents, club.Nickname,
+		season.Year, season.League.Name, season.Type)
+	return dg.calendar(calendarPath(clubPath(club, season)), name, games)
+}
+
+func (dg documentGenerator) seasonCalendar(season Season, games []Game) error {
+	name := fmt.Sprintf("%d %s %s", season.Year, season.League.Name, season.Type)
+	return dg.calendar(seasonCalendarPath(season), name, games)
+}
+
+func (dg documentGenerator) clubFeed(club Club, season Season, games []Game) error {
+	title := fmt.Sprintf("%s %s: %d %s %s", club.Represents, club.Nickname,
+		season.Year, season.League.Name, season.Type)
+	return dg.feed(clubPath(club, season),
+		newFeed(title, clubPath(club, season), "../../../..", games))
+}
 
-	path := dg.indexPath()
-	if file, err = createFile(path); err != nil {
+func (dg documentGenerator) leagueFeed(league League, games []Game) error {
+	title := fmt.Sprintf("%s %s", league.Name, league.Sport)
+	return dg.feed(leaguePath(league), newFeed(title, leaguePath(league), "..", games))
+}
+
+func (dg documentGenerator) indexFeed(games []Game) error {
+	return dg.feed(indexPath(), newFeed("Recent Games", indexPath(), ".", games))
+}
+
+// staticAssets writes the stylesheet and any other static assets, with
+// gzipped copies, under static in the site root
+func (dg documentGenerator) staticAssets() error {
+	assets, err := dg.assets.static()
+	if err != nil {
 		return err
 	}
-	defer file.Close()
+	for name, data := range assets {
+		data := data
+		err := dg.page(path.Join("/static", name), data, func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	pathGZ := fmt.Sprintf("%s.gz", path)
-	if fileGZ, err = createFile(pathGZ); err != nil {
-		return err
+func (dg documentGenerator) removeGamePage(game Game) error {
+	return dg.remove(gamePath(game))
+}
+
+func (dg documentGenerator) removeMatchupPage(league League, a, b Club) error {
+	return dg.remove(matchupPath(league, a, b))
+}
+
+// removeStalePages removes every page in the manifest that was not
+// written or skipped during this run, which after generating the whole
+// site are the pages of records that no longer exist
+func (dg documentGenerator) removeStalePages() error {
+	for _, sitePath := range dg.manifest.unseen() {
+		if err := dg.remove(sitePath); err != nil {
+			return err
+		}
 	}
-	defer fileGZ.Close()
-	gz, _ := gzip.NewWriterLevel(fileGZ, gzip.BestCompression)
-	defer gz.Close()
+	return nil
+}
 
-	doc := document{dg, io.MultiWriter(file, gz)}
-	return doc.index(games)
+// saveManifest records the pages written or removed during this run,
+// unless it is a dry run
+func (dg documentGenerator) saveManifest() error {
+	if dg.dryRun || !dg.manifest.changed() {
+		return nil
+	}
+	return dg.manifest.save()
 }
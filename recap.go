@@ -2,14 +2,24 @@ package main
 
 import (
 	"database/sql"
+	"embed"
 	"fmt"
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"recap/internal"
+	"strings"
 )
 
-var recapDir, recapDB string
+var recapDir, recapDB, recapDriver string
+
+//go:embed sql/migrations
+var migrations embed.FS
+
+//go:embed web/templates/*.tmpl web/static
+var assets embed.FS
 
 func init() {
 	if val, set := os.LookupEnv("RECAP_DIR"); set {
@@ -29,18 +39,72 @@ func init() {
 		recapDB = "recap"
 		fmt.Fprintf(os.Stderr, "RECAP_DB not set. Defaulting to \"%s\"\n", recapDB)
 	}
+
+	recapDriver = os.Getenv("RECAP_DRIVER")
+}
+
+// dataSource picks the database driver and connection string for db.
+// db may be a postgres:// or sqlite: URL, an SQLite file ending in
+// .db, .sqlite or .sqlite3, a Postgres keyword/value connection string,
+// or the bare name of a Postgres database on the local socket.
+// A driver named by RECAP_DRIVER takes precedence.
+func dataSource(driver, db string) (string, string) {
+	lower := strings.ToLower(db)
+	if driver == "" {
+		switch {
+		case strings.HasPrefix(lower, "postgres://"), strings.HasPrefix(lower, "postgresql://"):
+			driver = "postgres"
+		case strings.HasPrefix(lower, "sqlite:"),
+			strings.HasSuffix(lower, ".db"),
+			strings.HasSuffix(lower, ".sqlite"),
+			strings.HasSuffix(lower, ".sqlite3"):
+			driver = "sqlite3"
+		default:
+			driver = "postgres"
+		}
+	}
+
+	switch driver {
+	case "sqlite", "sqlite3":
+		path := strings.TrimPrefix(strings.TrimPrefix(db, "sqlite:"), "//")
+		separator := "?"
+		if strings.Contains(path, "?") {
+			separator = "&"
+		}
+		return "sqlite3", fmt.Sprintf("file:%s%s_foreign_keys=on&_busy_timeout=5000",
+			strings.TrimPrefix(path, "file:"), separator)
+	case "postgres":
+		if strings.Contains(db, "=") || strings.Contains(db, "://") {
+			return driver, db
+		}
+		return driver, fmt.Sprintf("dbname=%s host=/tmp sslmode=disable", db)
+	}
+	return driver, db
 }
 
 func main() {
-	connection := fmt.Sprintf("dbname=%s host=/tmp sslmode=disable", recapDB)
-	db, err := sql.Open("postgres", connection)
+	driver, connection := dataSource(recapDriver, recapDB)
+	db, err := sql.Open(driver, connection)
 	if err != nil {
 		panic(err)
 	}
 	defer db.Close()
 
+	schema, err := fs.Sub(migrations, "sql/migrations")
+	if err != nil {
+		panic(err)
+	}
+
+	web, err := fs.Sub(assets, "web")
+	if err != nil {
+		panic(err)
+	}
+
 	cli := new(internal.CLI)
-	cli.Initialize(db, recapDir)
+	if err := cli.Initialize(driver, db, schema, web, recapDir); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
 
-	cli.Start()
+	cli.Start(os.Args[1:])
 }
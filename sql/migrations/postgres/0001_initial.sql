@@ -1,5 +1,3 @@
-begin;
-
 create table if not exists sport (
     sport_id int generated by default as identity primary key,
     sport_name varchar(64) not null unique
@@ -227,5 +225,3 @@ natural join league
 natural join season
 natural join season_club
 natural join club;
-
-commit;
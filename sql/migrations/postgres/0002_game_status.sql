@@ -0,0 +1,76 @@
+alter table game
+    add column status varchar(16) not null default 'final'
+        check (status in ('scheduled', 'final', 'postponed', 'cancelled', 'forfeit')),
+    add column game_time time;
+
+drop function new_game(varchar, int, int, int, int, int, date, varchar, varchar);
+
+create or replace
+function new_game(league_code varchar(8), season_id int,
+                  home_id int, home_score int,
+                  away_id int, away_score int,
+                  game_date date,
+                  title varchar,
+                  venue varchar,
+                  status varchar,
+                  game_time time)
+returns int as $$
+declare
+    new_game_id int;
+begin
+    insert into game(league_code, game_date, title, venue, status, game_time)
+    values(league_code, game_date, title, venue, status, game_time)
+    returning game_id into new_game_id;
+
+    insert into game_season(game_id, league_code, season_id)
+    values(new_game_id, league_code, season_id);
+
+    insert into game_club(game_id, league_code, season_id, club_id, score)
+    values (new_game_id, league_code, season_id, home_id, home_score),
+           (new_game_id, league_code, season_id, away_id, away_score);
+
+    insert into game_club_home(game_id, club_id)
+    values(new_game_id, home_id);
+
+    return new_game_id;
+end;
+$$ language plpgsql;
+
+create or replace view game_view as 
+select
+    sport.sport_name, league.league_code, league.league_name,
+    season.season_id, season.start_year, season.season_type,
+    season.exhibition,
+    game.game_id, game.game_date, game.title, game.venue,
+    "hc".club_id "home_id", hc.club_iteration "home_iteration",
+    "hc".represents "home_represents", "hc".nickname "home_nickname",
+    "home".score "home_score", "ac".club_id "away_id",
+    "ac".club_iteration "away_iteration",
+    "ac".represents "away_represents", "ac".nickname "away_nickname",
+    "away".score "away_score",
+    game.status, game.game_time
+from
+    sport
+    natural join league
+    natural join season
+    natural join game_season
+    natural join game
+    natural join game_club_home
+    natural join game_club "home"
+    join season_club "hsc"
+        on "hsc".season_id = "home".season_id
+        and "hsc".league_code = "home".league_code
+        and "hsc".club_id = "home".club_id
+    join club "hc"
+        on "hc".club_id = "hsc".club_id
+        and "hc".club_iteration = "hsc".club_iteration
+    join game_club "away"
+        on "away".game_id = game.game_id
+        and "away".club_id != "home".club_id
+    join season_club "asc"
+        on "asc".season_id = "away".season_id
+        and "asc".league_code = "away".league_code
+        and "asc".club_id = "away".club_id
+    join club "ac"
+        on "ac".club_id = "asc".club_id
+        and "ac".club_iteration = "asc".club_iteration;
@@ -0,0 +1,26 @@
+alter table sport
+    add column periods int not null default 0 check (periods >= 0),
+    add column period_name varchar(32) not null default 'Period',
+    add column overtime_name varchar(32) not null default 'OT';
+
+update sport set periods = 4, period_name = 'Quarter'
+where lower(sport_name) in ('basketball', 'football');
+
+update sport set periods = 3
+where lower(sport_name) in ('hockey', 'ice hockey');
+
+update sport set periods = 9, period_name = 'Inning', overtime_name = ''
+where lower(sport_name) in ('baseball');
+
+update sport set periods = 2, period_name = 'Half', overtime_name = 'ET'
+where lower(sport_name) in ('soccer');
+
+create table if not exists game_period (
+    game_id int,
+    period int check (period > 0),
+    home_score int not null default 0 check (home_score >= 0),
+    away_score int not null default 0 check (away_score >= 0),
+    primary key(game_id, period),
+    foreign key(game_id) references game(game_id)
+    on delete cascade
+);
@@ -0,0 +1,48 @@
+alter table game
+    add column status varchar(16) not null default 'final'
+        check (status in ('scheduled', 'final', 'postponed', 'cancelled', 'forfeit'));
+
+alter table game
+    add column game_time text
+        check (game_time is null or game_time = strftime('%H:%M', game_time));
+
+drop view game_view;
+
+create view game_view as
+select
+    sport.sport_name, league.league_code, league.league_name,
+    season.season_id, season.start_year, season.season_type,
+    season.exhibition,
+    game.game_id, game.game_date, game.title, game.venue,
+    "hc".club_id "home_id", hc.club_iteration "home_iteration",
+    "hc".represents "home_represents", "hc".nickname "home_nickname",
+    "home".score "home_score", "ac".club_id "away_id",
+    "ac".club_iteration "away_iteration",
+    "ac".represents "away_represents", "ac".nickname "away_nickname",
+    "away".score "away_score",
+    game.status, game.game_time
+from
+    sport
+    natural join league
+    natural join season
+    natural join game_season
+    natural join game
+    natural join game_club_home
+    natural join game_club "home"
+    join season_club "hsc"
+        on "hsc".season_id = "home".season_id
+        and "hsc".league_code = "home".league_code
+        and "hsc".club_id = "home".club_id
+    join club "hc"
+        on "hc".club_id = "hsc".club_id
+        and "hc".club_iteration = "hsc".club_iteration
+    join game_club "away"
+        on "away".game_id = game.game_id
+        and "away".club_id != "home".club_id
+    join season_club "asc"
+        on "asc".season_id = "away".season_id
+        and "asc".league_code = "away".league_code
+        and "asc".club_id = "away".club_id
+    join club "ac"
+        on "ac".club_id = "asc".club_id
+        and "ac".club_iteration = "asc".club_iteration;
@@ -0,0 +1,62 @@
+create table if not exists player (
+    player_id integer primary key,
+    player_name varchar(128) not null
+);
+
+create table if not exists season_roster (
+    season_id int,
+    league_code varchar(8),
+    club_id int,
+    player_id int,
+    primary key(season_id, league_code, club_id, player_id),
+    foreign key(season_id, league_code, club_id) references season_club(season_id, league_code, club_id)
+    on delete cascade,
+    foreign key(player_id) references player
+);
+
+create table if not exists stat (
+    stat_id integer primary key,
+    sport_id int not null,
+    stat_code varchar(8) not null,
+    stat_name varchar(64) not null,
+    unique(sport_id, stat_code),
+    foreign key(sport_id) references sport
+);
+
+create table if not exists game_player (
+    game_id int,
+    player_id int,
+    club_id int not null,
+    primary key(game_id, player_id),
+    foreign key(game_id) references game(game_id)
+    on delete cascade,
+    foreign key(player_id) references player
+);
+
+create table if not exists game_stat (
+    game_id int,
+    player_id int,
+    stat_id int,
+    value int not null default 0,
+    primary key(game_id, player_id, stat_id),
+    foreign key(game_id, player_id) references game_player
+    on delete cascade,
+    foreign key(stat_id) references stat
+);
+
+insert into stat(sport_id, stat_code, stat_name)
+select sport_id, stat.code, stat.name
+from sport, (select 1 n, 'PTS' code, 'Points' name
+             union all select 2, 'REB', 'Rebounds'
+             union all select 3, 'AST', 'Assists') stat
+where lower(sport_name) = 'basketball'
+order by stat.n;
+
+insert into stat(sport_id, stat_code, stat_name)
+select sport_id, stat.code, stat.name
+from sport, (select 1 n, 'PASS' code, 'Passing yards' name
+             union all select 2, 'RUSH', 'Rushing yards'
+             union all select 3, 'REC', 'Receiving yards'
+             union all select 4, 'TD', 'Touchdowns') stat
+where lower(sport_name) = 'football'
+order by stat.n;
@@ -1,6 +1,19 @@
 begin;
 
-insert into sport values (1, 'Basketball'), (2, 'Football') on conflict do nothing;
+insert into sport(sport_id, sport_name, periods, period_name, overtime_name) values
+                 (1, 'Basketball', 4, 'Quarter', 'OT'),
+                 (2, 'Football', 4, 'Quarter', 'OT')
+on conflict do nothing;
+
+insert into stat(sport_id, stat_code, stat_name) values
+                (1, 'PTS', 'Points'),
+                (1, 'REB', 'Rebounds'),
+                (1, 'AST', 'Assists'),
+                (2, 'PASS', 'Passing yards'),
+                (2, 'RUSH', 'Rushing yards'),
+                (2, 'REC', 'Receiving yards'),
+                (2, 'TD', 'Touchdowns')
+on conflict do nothing;
 
 insert into league values('NBA', 'NBA', 1), ('NFL', 'NFL', 2) on conflict do nothing;
 